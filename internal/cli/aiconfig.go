@@ -0,0 +1,331 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/arnislvdev/go-guru-ui/internal/infrastructure/ai"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// providerKeyEnv lists the provider's own API key variable, used when GuruUI has no key set
+var providerKeyEnv = map[string]string{
+	"openai":    "OPENAI_API_KEY",
+	"anthropic": "ANTHROPIC_API_KEY",
+}
+
+// providerNames returns the providers from ai.provider, which may be a single
+// name, a list, or a comma-separated string (handy in GURUUI_AI_PROVIDER)
+func providerNames() []string {
+	names := stringList("ai.provider")
+	if len(names) == 0 {
+		names = []string{ai.DefaultConfig().Provider}
+	}
+	return names
+}
+
+// providerSetting reads one provider's value for key. ai.<provider>.<key> wins;
+// the shared ai.<key> is used for the primary provider only, so in a fallback
+// chain one provider's key or endpoint is never sent to another.
+func providerSetting(provider, key string, primary bool) string {
+	if value := viper.GetString("ai." + provider + "." + key); value != "" {
+		return value
+	}
+	if primary {
+		return viper.GetString("ai." + key)
+	}
+	return ""
+}
+
+// loadAIConfig builds the AI settings for one provider for this run.
+// primary marks the first provider in ai.provider, which also gets the shared
+// provider settings and the --model flag.
+//
+// Later sources win over earlier ones:
+//  1. built-in defaults
+//  2. the provider's own key variable (e.g. OPENAI_API_KEY), for the API key only
+//  3. the settings file (ai.api_key, ai.model, ai.models.<task>, ai.max_tokens, ai.timeout, ai.http.*, ai.<provider>.*, ...)
+//  4. GURUUI_* environment variables (e.g. GURUUI_AI_API_KEY, GURUUI_AI_ANTHROPIC_API_KEY)
+//  5. the --model, --max-tokens and --timeout flags; --model is used for every task
+func loadAIConfig(cmd *cobra.Command, provider string, primary bool) (*ai.Config, error) {
+	cfg := ai.DefaultConfig()
+	cfg.Provider = provider
+
+	// Settings file and GURUUI_* variables (viper already ranks these)
+	perProvider := map[string]*string{
+		"api_key":      &cfg.APIKey,
+		"model":        &cfg.Model,
+		"base_url":     &cfg.BaseURL,
+		"organization": &cfg.Organization,
+		"api_type":     &cfg.APIType,
+		"api_version":  &cfg.APIVersion,
+		"deployment":   &cfg.Deployment,
+	}
+	for key, field := range perProvider {
+		*field = providerSetting(provider, key, primary)
+	}
+
+	// A model per task, falling back to the model above
+	for _, task := range ai.Tasks() {
+		if model := providerSetting(provider, "models."+task, primary); model != "" {
+			if cfg.Models == nil {
+				cfg.Models = make(map[string]string)
+			}
+			cfg.Models[task] = model
+		}
+	}
+
+	cfg.Headers = viper.GetStringMapString("ai." + provider + ".headers")
+	if len(cfg.Headers) == 0 && primary {
+		cfg.Headers = viper.GetStringMapString("ai.headers")
+	}
+
+	httpConfig, err := loadHTTPConfig(provider)
+	if err != nil {
+		return nil, err
+	}
+	cfg.HTTP = httpConfig
+
+	if maxTokens := viper.GetInt("ai.max_tokens"); maxTokens > 0 {
+		cfg.MaxTokens = maxTokens
+	}
+	if timeout := viper.GetString("ai.timeout"); timeout != "" {
+		d, err := parseTimeout(timeout)
+		if err != nil {
+			return nil, fmt.Errorf("invalid ai.timeout: %w", err)
+		}
+		cfg.Timeout = d
+	}
+
+	// Fall back to the provider's own variable
+	if cfg.APIKey == "" {
+		if name, ok := providerKeyEnv[provider]; ok {
+			cfg.APIKey = os.Getenv(name)
+		}
+	}
+
+	// Flags beat everything else
+	if primary && cmd.Flags().Changed("model") {
+		cfg.Model = modelFlag
+		cfg.Models = nil
+	}
+	if cmd.Flags().Changed("max-tokens") {
+		if maxTokensFlag <= 0 {
+			return nil, fmt.Errorf("--max-tokens must be greater than 0, got %d", maxTokensFlag)
+		}
+		cfg.MaxTokens = maxTokensFlag
+	}
+	if cmd.Flags().Changed("timeout") {
+		cfg.Timeout = timeoutFlag
+	}
+
+	return cfg, nil
+}
+
+// newAIClient builds the client for this run. Normally that is the configured
+// providers; cassette.mode can record their answers to cassette.file, or
+// replay answers from it without contacting any provider.
+func newAIClient(cmd *cobra.Command) (ai.Client, error) {
+	cassetteMode := viper.GetString("cassette.mode")
+	if cassetteMode == "" {
+		return newProviderClient(cmd)
+	}
+	if cassetteMode != ai.CassetteRecord && cassetteMode != ai.CassetteReplay {
+		return nil, fmt.Errorf("invalid cassette.mode %q (use %s or %s)", cassetteMode, ai.CassetteRecord, ai.CassetteReplay)
+	}
+
+	path := viper.GetString("cassette.file")
+	if path == "" {
+		return nil, fmt.Errorf("cassette.mode is %s but cassette.file is not set", cassetteMode)
+	}
+	cassette, err := ai.LoadCassette(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if cassetteMode == ai.CassetteReplay {
+		return ai.NewReplayClient(cassette), nil
+	}
+
+	client, err := newProviderClient(cmd)
+	if err != nil {
+		return nil, err
+	}
+	recorder := ai.NewRecordingClient(client, cassette)
+	recorder.OnRecordError = func(err error) {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+	}
+	return recorder, nil
+}
+
+// newProviderClient builds the client for the providers chosen in settings. Each
+// provider retries on its own and keeps its answers in the response cache;
+// with more than one, the next is tried when a provider fails. Providers
+// without an API key are skipped, and if that leaves none the offline
+// explainer answers instead.
+func newProviderClient(cmd *cobra.Command) (ai.Client, error) {
+	policy, err := loadRetryPolicy()
+	if err != nil {
+		return nil, err
+	}
+
+	prompts, err := loadPrompts()
+	if err != nil {
+		return nil, err
+	}
+
+	responses := openResponseCache()
+
+	var clients []ai.Client
+	var missingKey error
+	for i, name := range providerNames() {
+		cfg, err := loadAIConfig(cmd, name, i == 0)
+		if err != nil {
+			return nil, err
+		}
+		cfg.Prompts = prompts
+
+		client, err := ai.New(cfg)
+		switch {
+		case errors.Is(err, ai.ErrMissingAPIKey):
+			if missingKey == nil {
+				missingKey = missingKeyError(name)
+			}
+			if verbose {
+				fmt.Fprintf(os.Stderr, "Skipping provider %s: %v\n", name, missingKeyError(name))
+			}
+			continue
+		case err != nil:
+			return nil, fmt.Errorf("failed to set up AI client: %w", err)
+		}
+
+		// The offline explainer answers instantly and never fails for passing reasons
+		if name == ai.ProviderOffline {
+			clients = append(clients, client)
+			continue
+		}
+		reportHTTP(name, cfg.HTTP)
+
+		var wrapped ai.Client = newRetryClient(client, policy)
+		if responses != nil {
+			wrapped = newCachedClient(wrapped, responses, cfg)
+		}
+		clients = append(clients, wrapped)
+	}
+
+	if len(clients) == 0 {
+		offline, err := ai.NewOfflineClient()
+		if err != nil {
+			return nil, fmt.Errorf("failed to set up AI client: %w", err)
+		}
+		fmt.Fprintf(os.Stderr, "Using built-in offline answers (%v)\n\n", missingKey)
+		return offline, nil
+	}
+
+	if len(clients) == 1 {
+		return clients[0], nil
+	}
+
+	fallback := ai.NewFallbackClient(clients...)
+	if verbose {
+		fallback.OnFallback = func(failed, next string, err error) {
+			fmt.Fprintf(os.Stderr, "%s failed (%v); trying %s\n", failed, err, next)
+		}
+	}
+	return fallback, nil
+}
+
+// newRetryClient wraps client in the retry policy, reporting retries in verbose mode
+func newRetryClient(client ai.Client, policy ai.RetryPolicy) *ai.RetryClient {
+	retry := ai.NewRetryClient(client, policy)
+	if verbose {
+		retry.OnRetry = func(attempt int, wait time.Duration, err error) {
+			fmt.Fprintf(os.Stderr, "%s failed (%v); retry %d of %d in %s\n",
+				retry.GetProvider(), err, attempt-1, policy.MaxAttempts-1, wait.Round(time.Millisecond))
+		}
+	}
+	return retry
+}
+
+// newCachedClient keeps client's answers in responses, reporting hits in verbose mode
+func newCachedClient(client ai.Client, responses ai.Cache, cfg *ai.Config) *ai.CachedClient {
+	cached := ai.NewCachedClient(client, responses, cfg)
+	if verbose {
+		cached.OnHit = func(provider string) {
+			fmt.Fprintf(os.Stderr, "Using cached answer from %s (--no-cache to ask again)\n", provider)
+		}
+	}
+	return cached
+}
+
+// reportProvider tells verbose users which provider answered
+func reportProvider(client ai.Client) {
+	if verbose {
+		fmt.Fprintf(os.Stderr, "Answered by %s\n", client.GetProvider())
+	}
+}
+
+// loadRetryPolicy reads ai.retry.* on top of the default retry policy
+func loadRetryPolicy() (ai.RetryPolicy, error) {
+	policy := ai.DefaultRetryPolicy()
+
+	if viper.IsSet("ai.retry.max_attempts") {
+		policy.MaxAttempts = viper.GetInt("ai.retry.max_attempts")
+	}
+
+	durations := []struct {
+		key   string
+		value *time.Duration
+	}{
+		{"ai.retry.base_delay", &policy.BaseDelay},
+		{"ai.retry.max_delay", &policy.MaxDelay},
+		{"ai.retry.max_elapsed", &policy.MaxElapsed},
+	}
+	for _, d := range durations {
+		raw := viper.GetString(d.key)
+		if raw == "" {
+			continue
+		}
+		parsed, err := parseTimeout(raw)
+		if err != nil {
+			return policy, fmt.Errorf("invalid %s: %w", d.key, err)
+		}
+		*d.value = parsed
+	}
+
+	return policy, nil
+}
+
+// missingKeyError tells the user every place an API key can come from
+func missingKeyError(provider string) error {
+	hint := "run 'guruui config set --api-key <key>' or set GURUUI_AI_API_KEY"
+	if name, ok := providerKeyEnv[provider]; ok {
+		hint += " or " + name
+	}
+	return fmt.Errorf("%w for %s: %s", ai.ErrMissingAPIKey, provider, hint)
+}
+
+// parseTimeout reads a duration like "45s" or "2m"; a bare number means seconds
+func parseTimeout(value string) (time.Duration, error) {
+	if seconds, err := strconv.Atoi(value); err == nil {
+		return time.Duration(seconds) * time.Second, nil
+	}
+	return time.ParseDuration(value)
+}
+
+// aiFailure wraps an AI error with what we were doing, except for timeouts and
+// cancellations, which already read well on their own
+func aiFailure(action string, err error) error {
+	var timeout *ai.TimeoutError
+	if errors.As(err, &timeout) {
+		return timeout
+	}
+	if errors.Is(err, ai.ErrCanceled) {
+		return ai.ErrCanceled
+	}
+	return fmt.Errorf("%s: %w", action, err)
+}
@@ -0,0 +1,117 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/arnislvdev/go-guru-ui/internal/infrastructure/cache"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// Response cache defaults, used when cache.* is not set
+const (
+	defaultCacheTTL       = 7 * 24 * time.Hour
+	defaultCacheMaxSizeMB = 50
+)
+
+var cacheCmd = &cobra.Command{
+	Use:   "cache",
+	Short: "Manage saved AI answers",
+	Long: `GuruUI saves AI answers on disk and reuses them when the same question
+is asked of the same provider and model again. Use --no-cache to skip it for one run.
+
+Examples:
+  guruui cache stats
+  guruui cache clear`,
+}
+
+var cacheClearCmd = &cobra.Command{
+	Use:   "clear",
+	Short: "Delete all saved answers",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		responses, err := loadResponseCache()
+		if err != nil {
+			return err
+		}
+
+		removed, err := responses.Clear()
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("Removed %d saved answers\n", removed)
+		return nil
+	},
+}
+
+var cacheStatsCmd = &cobra.Command{
+	Use:   "stats",
+	Short: "Show how much is saved",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		responses, err := loadResponseCache()
+		if err != nil {
+			return err
+		}
+
+		stats, err := responses.Stats()
+		if err != nil {
+			return err
+		}
+
+		fmt.Println("Response Cache:")
+		fmt.Printf("Enabled: %t\n", viper.GetBool("cache.enabled"))
+		fmt.Printf("Location: %s\n", stats.Dir)
+		fmt.Printf("Entries: %d (%d expired)\n", stats.Entries, stats.Expired)
+		fmt.Printf("Size: %.1f of %.0f MB\n", float64(stats.Bytes)/(1<<20), float64(stats.MaxBytes)/(1<<20))
+		fmt.Printf("Keep For: %s\n", stats.TTL)
+		return nil
+	},
+}
+
+func init() {
+	cacheCmd.AddCommand(cacheClearCmd)
+	cacheCmd.AddCommand(cacheStatsCmd)
+
+	viper.SetDefault("cache.enabled", true)
+	viper.SetDefault("cache.ttl", defaultCacheTTL.String())
+	viper.SetDefault("cache.max_size_mb", defaultCacheMaxSizeMB)
+}
+
+// loadResponseCache opens the cache described by cache.dir, cache.ttl and cache.max_size_mb
+func loadResponseCache() (*cache.DiskCache, error) {
+	dir := viper.GetString("cache.dir")
+	if dir == "" {
+		var err error
+		if dir, err = cache.DefaultDir(); err != nil {
+			return nil, err
+		}
+	}
+
+	ttl, err := parseTimeout(viper.GetString("cache.ttl"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid cache.ttl: %w", err)
+	}
+
+	maxBytes := int64(viper.GetFloat64("cache.max_size_mb") * (1 << 20))
+	return cache.NewDiskCache(dir, ttl, maxBytes)
+}
+
+// openResponseCache returns the cache for this run, or nil when it is turned
+// off or can't be opened. A broken cache only costs a request, so it never
+// stops the command.
+func openResponseCache() *cache.DiskCache {
+	if noCache || !viper.GetBool("cache.enabled") {
+		return nil
+	}
+
+	responses, err := loadResponseCache()
+	if err != nil {
+		if verbose {
+			fmt.Fprintf(os.Stderr, "Not using the response cache: %v\n", err)
+		}
+		return nil
+	}
+	return responses
+}
@@ -0,0 +1,210 @@
+package cli
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/arnislvdev/go-guru-ui/internal/domain"
+	"github.com/arnislvdev/go-guru-ui/internal/infrastructure/ai"
+	"github.com/arnislvdev/go-guru-ui/internal/infrastructure/chat"
+	"github.com/arnislvdev/go-guru-ui/internal/usecase"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var chatCmd = &cobra.Command{
+	Use:   "chat [error_message]",
+	Short: "Explain an error, then ask follow-up questions about it",
+	Long: `Explain an error and keep talking about it: ask why, ask for an example,
+ask what else could cause it. Type exit (or press Ctrl-D) to stop.
+
+Every chat is saved, so you can pick it up later by its ID.
+
+Examples:
+  guruui chat "undefined: fmt"
+  guruui chat --list
+  guruui chat --resume 3f9a2c1d`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		list, _ := cmd.Flags().GetBool("list")
+		resume, _ := cmd.Flags().GetString("resume")
+
+		store, err := loadChatStore()
+		if err != nil {
+			return err
+		}
+		if list {
+			return listChats(cmd.OutOrStdout(), store)
+		}
+
+		switch {
+		case resume != "" && len(args) > 0:
+			return fmt.Errorf("give an error message or --resume, not both")
+		case resume == "" && len(args) == 0:
+			return fmt.Errorf("give an error message to explain, or --resume a saved chat")
+		}
+
+		client, err := newAIClient(cmd)
+		if err != nil {
+			return err
+		}
+		opts, err := redactOptions()
+		if err != nil {
+			return err
+		}
+		chatter := usecase.NewErrorChat(client, opts...)
+		chatter.OnRedact = reportRedaction
+
+		var conv *domain.Conversation
+		if resume != "" {
+			if conv, err = store.Load(resume); err != nil {
+				return err
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "Back to: %s\n\n%s\n", conv.Error.Message, lastAnswer(conv))
+		} else {
+			file, _ := cmd.Flags().GetString("file")
+			line, _ := cmd.Flags().GetInt("line")
+			toolOpts, err := toolOptions(cmd, client)
+			if err != nil {
+				return err
+			}
+			if conv, err = explainForChat(cmd, client, chatter, append(opts, toolOpts...), args[0], file, line); err != nil {
+				return err
+			}
+		}
+
+		return runChat(cmd, client, chatter, store, conv)
+	},
+}
+
+func init() {
+	chatCmd.Flags().StringP("file", "f", "", "source file path for context")
+	chatCmd.Flags().IntP("line", "l", 0, "line number for context")
+	chatCmd.Flags().String("resume", "", "carry on a saved chat, by ID")
+	chatCmd.Flags().Bool("list", false, "list saved chats")
+	chatCmd.Flags().Bool("investigate", false, "let the AI read your code (read-only) before explaining")
+}
+
+// explainForChat explains the error as 'guruui explain' does and starts a conversation with the answer
+func explainForChat(cmd *cobra.Command, client ai.Client, chatter *usecase.ErrorChat, opts []usecase.Option, errorMsg, file string, line int) (*domain.Conversation, error) {
+	explainer := usecase.NewErrorExplainer(client, opts...)
+	explainer.OnRedact = reportRedaction
+	explainer.OnToolCall = reportToolCall
+
+	ctx, meter := meterUsage(cmd)
+	defer recordUsage(cmd, meter)
+
+	var explanation strings.Builder
+	out := cmd.OutOrStdout()
+	err := explainer.ExplainStream(ctx, errorMsg, file, line, mode, func(chunk string) {
+		explanation.WriteString(chunk)
+		fmt.Fprint(out, chunk)
+	})
+	if err != nil {
+		return nil, aiFailure("failed to explain error", err)
+	}
+	fmt.Fprintln(out)
+	reportProvider(client)
+
+	return chatter.Start(explainer.Parse(errorMsg, file, line), explanation.String()), nil
+}
+
+// runChat answers questions from stdin until exit or end of input, saving the conversation after each answer
+func runChat(cmd *cobra.Command, client ai.Client, chatter *usecase.ErrorChat, store *chat.Store, conv *domain.Conversation) error {
+	if err := store.Save(conv); err != nil {
+		return err
+	}
+
+	out := cmd.OutOrStdout()
+	fmt.Fprintf(out, "\nAsk a follow-up question, or type exit. (chat %s: guruui chat --resume %s)\n", conv.ID, conv.ID)
+
+	input := bufio.NewScanner(cmd.InOrStdin())
+	for {
+		fmt.Fprint(out, "\n> ")
+		if !input.Scan() {
+			fmt.Fprintln(out)
+			return input.Err()
+		}
+
+		question := strings.TrimSpace(input.Text())
+		switch strings.ToLower(question) {
+		case "":
+			continue
+		case "exit", "quit":
+			return nil
+		}
+
+		ctx, meter := meterUsage(cmd)
+		reply, err := chatter.Ask(ctx, conv, question)
+		recordUsage(cmd, meter)
+		if errors.Is(err, ai.ErrCanceled) {
+			return ai.ErrCanceled
+		}
+		if err != nil {
+			// One failed question shouldn't end the chat
+			fmt.Fprintf(os.Stderr, "Error: %v\n", aiFailure("failed to answer", err))
+			continue
+		}
+
+		fmt.Fprintf(out, "\n%s\n", reply)
+		reportProvider(client)
+		if err := store.Save(conv); err != nil {
+			return err
+		}
+	}
+}
+
+// listChats prints the saved chats, newest first
+func listChats(out io.Writer, store *chat.Store) error {
+	convs, err := store.List()
+	if err != nil {
+		return err
+	}
+	if len(convs) == 0 {
+		fmt.Fprintln(out, "No saved chats")
+		return nil
+	}
+
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tUPDATED\tQUESTIONS\tERROR")
+	for _, conv := range convs {
+		fmt.Fprintf(w, "%s\t%s\t%d\t%s\n", conv.ID, conv.Updated.Format("2006-01-02 15:04"), len(conv.Messages)/2, firstLine(conv.Error.Message, 60))
+	}
+	return w.Flush()
+}
+
+// loadChatStore opens the chat store at chat.dir, or the default location
+func loadChatStore() (*chat.Store, error) {
+	dir := viper.GetString("chat.dir")
+	if dir == "" {
+		var err error
+		if dir, err = chat.DefaultDir(); err != nil {
+			return nil, err
+		}
+	}
+	return chat.NewStore(dir), nil
+}
+
+// lastAnswer returns the most recent answer in conv
+func lastAnswer(conv *domain.Conversation) string {
+	for i := len(conv.Messages) - 1; i >= 0; i-- {
+		if conv.Messages[i].Role == domain.RoleAssistant {
+			return conv.Messages[i].Content
+		}
+	}
+	return ""
+}
+
+// firstLine returns the first line of text, cut to at most max characters
+func firstLine(text string, max int) string {
+	text, _, _ = strings.Cut(text, "\n")
+	if runes := []rune(text); len(runes) > max {
+		return string(runes[:max-1]) + "…"
+	}
+	return text
+}
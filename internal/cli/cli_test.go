@@ -0,0 +1,267 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/arnislvdev/go-guru-ui/internal/infrastructure/ai"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// runCLI runs guruui with args, answering AI requests from the cassette in
+// testdata, and returns what it printed. No provider is contacted.
+func runCLI(t *testing.T, cassette string, args ...string) (string, error) {
+	t.Helper()
+	return runCLIWith(t, map[string]string{
+		"GURUUI_CASSETTE_MODE": ai.CassetteReplay,
+		"GURUUI_CASSETTE_FILE": filepath.Join("testdata", cassette),
+	}, args...)
+}
+
+// runCLIWith runs guruui with args in a clean environment plus env
+func runCLIWith(t *testing.T, env map[string]string, args ...string) (string, error) {
+	t.Helper()
+
+	// Keep the developer's own settings, keys and caches out of the test
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(home, "config"))
+	t.Setenv("XDG_CACHE_HOME", filepath.Join(home, "cache"))
+	for _, name := range []string{
+		"OPENAI_API_KEY", "ANTHROPIC_API_KEY",
+		"GURUUI_AI_PROVIDER", "GURUUI_AI_API_KEY", "GURUUI_AI_MODEL", "GURUUI_AI_BASE_URL",
+		"GURUUI_AI_MODELS_EXPLAIN", "GURUUI_AI_MODELS_TRANSLATE", "GURUUI_AI_MODELS_CHAT",
+		"GURUUI_CASSETTE_MODE", "GURUUI_CASSETTE_FILE",
+	} {
+		t.Setenv(name, "")
+	}
+	for name, value := range env {
+		t.Setenv(name, value)
+	}
+
+	// Flags keep their values between runs of the same command tree
+	resetFlags(rootCmd)
+
+	var out bytes.Buffer
+	rootCmd.SetOut(&out)
+	rootCmd.SetErr(&out)
+	rootCmd.SetArgs(args)
+	err := rootCmd.ExecuteContext(context.Background())
+	return out.String(), err
+}
+
+// resetFlags puts every flag of cmd and its subcommands back to its default
+func resetFlags(cmd *cobra.Command) {
+	reset := func(f *pflag.Flag) {
+		f.Value.Set(f.DefValue)
+		f.Changed = false
+	}
+	cmd.Flags().VisitAll(reset)
+	cmd.PersistentFlags().VisitAll(reset)
+	for _, sub := range cmd.Commands() {
+		resetFlags(sub)
+	}
+}
+
+func TestExplainCommand(t *testing.T) {
+	out, err := runCLI(t, "explain.json", "explain", "undefined: fmt")
+	if err != nil {
+		t.Fatalf("explain returned error: %v", err)
+	}
+
+	want := "The name fmt is not defined because the fmt package is not imported. Add import \"fmt\" at the top of the file.\n"
+	if out != want {
+		t.Errorf("output = %q, want %q", out, want)
+	}
+}
+
+func TestExplainCommandWithLocation(t *testing.T) {
+	out, err := runCLI(t, "explain.json", "explain", "x declared and not used", "--file", "main.go", "--line", "7")
+	if err != nil {
+		t.Fatalf("explain returned error: %v", err)
+	}
+	if !strings.HasPrefix(out, "You created the variable x on line 7") {
+		t.Errorf("output = %q", out)
+	}
+}
+
+func TestExplainCommandWTFMode(t *testing.T) {
+	out, err := runCLI(t, "explain.json", "--mode", "wtf", "explain", "undefined: fmt")
+	if err != nil {
+		t.Fatalf("explain returned error: %v", err)
+	}
+
+	explanation := "The name fmt is not defined because the fmt package is not imported."
+	if !strings.Contains(out, explanation) || strings.HasPrefix(out, explanation) {
+		t.Errorf("wtf output should wrap the explanation, got %q", out)
+	}
+}
+
+func TestExplainCommandUnrecorded(t *testing.T) {
+	_, err := runCLI(t, "explain.json", "explain", "missing return")
+	if !errors.Is(err, ai.ErrNoRecording) {
+		t.Errorf("err = %v, want ErrNoRecording", err)
+	}
+}
+
+func TestExplainBuildOutput(t *testing.T) {
+	out, err := runCLI(t, "build.json", "explain", "--input", filepath.Join("testdata", "build.log"))
+	if err != nil {
+		t.Fatalf("explain --input returned error: %v", err)
+	}
+
+	// Grouped by file, in position order, whatever order the compiler used
+	var order []int
+	for _, want := range []string{"./main.go\n", `4:2: "os" imported and not used`, "9:2: declared and not used: x", "./util.go\n", "7:1: missing return", "too many errors"} {
+		i := strings.Index(out, want)
+		if i < 0 {
+			t.Fatalf("output = %q, want it to contain %q", out, want)
+		}
+		order = append(order, i)
+	}
+	for i := 1; i < len(order); i++ {
+		if order[i] < order[i-1] {
+			t.Fatalf("output = %q, want files grouped and diagnostics in position order", out)
+		}
+	}
+
+	rootCmd.SetIn(strings.NewReader("nothing to see here\n"))
+	defer rootCmd.SetIn(nil)
+	if _, err := runCLI(t, "build.json", "explain", "-"); err == nil || !strings.Contains(err.Error(), "no Go compiler") {
+		t.Errorf("explain - with no diagnostics = %v, want an error", err)
+	}
+}
+
+func TestExplainGoroutines(t *testing.T) {
+	rootCmd.SetIn(strings.NewReader(""))
+	defer rootCmd.SetIn(nil)
+	if _, err := runCLI(t, "goroutines.json", "explain", "--goroutines"); err == nil {
+		t.Error("explain --goroutines with nothing on standard input should fail")
+	}
+
+	out, err := runCLI(t, "goroutines.json", "explain", "--goroutines", "--input", filepath.Join("testdata", "deadlock.txt"))
+	if err != nil {
+		t.Fatalf("explain --goroutines returned error: %v", err)
+	}
+	summary := strings.Index(out, "Goroutine dump: 2 goroutines in 2 groups.")
+	explanation := strings.Index(out, "Every goroutine is blocked")
+	if summary < 0 || explanation < summary || !strings.Contains(out, "- 1 x chan send, started by main.main") {
+		t.Errorf("output = %q, want the summary then the explanation", out)
+	}
+}
+
+func TestTranslateCommand(t *testing.T) {
+	out, err := runCLI(t, "translate.json", "translate", "how do I check disk space", "--context", "linux")
+	if err != nil {
+		t.Fatalf("translate returned error: %v", err)
+	}
+
+	want := "Command: df -h\n\n" +
+		"Flags:\n  -h  show sizes in K, M and G instead of blocks\n\n" +
+		"Explanation: Shows how much space is used and free on each mounted filesystem.\n"
+	if out != want {
+		t.Errorf("output = %q, want %q", out, want)
+	}
+}
+
+func TestRecordCassette(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "recorded.json")
+	out, err := runCLIWith(t, map[string]string{
+		"GURUUI_AI_PROVIDER":   ai.ProviderOffline,
+		"GURUUI_CASSETTE_MODE": ai.CassetteRecord,
+		"GURUUI_CASSETTE_FILE": path,
+	}, "explain", "missing return")
+	if err != nil {
+		t.Fatalf("explain returned error: %v", err)
+	}
+
+	cassette, err := ai.LoadCassette(path)
+	if err != nil {
+		t.Fatalf("LoadCassette returned error: %v", err)
+	}
+	if cassette.Len() != 1 || cassette.Interactions[0].Response.Provider != ai.ProviderOffline {
+		t.Fatalf("cassette = %+v, want one answer from the offline provider", cassette.Interactions)
+	}
+	if want := cassette.Interactions[0].Response.Explanation + "\n"; out != want {
+		t.Errorf("output = %q, want the recorded %q", out, want)
+	}
+}
+
+func TestChatCommand(t *testing.T) {
+	chats := t.TempDir()
+	env := map[string]string{
+		"GURUUI_CASSETTE_MODE": ai.CassetteReplay,
+		"GURUUI_CASSETTE_FILE": filepath.Join("testdata", "chat.json"),
+		"GURUUI_CHAT_DIR":      chats,
+	}
+
+	rootCmd.SetIn(strings.NewReader("show me an example\n\nexit\n"))
+	defer rootCmd.SetIn(nil)
+	out, err := runCLIWith(t, env, "explain", "undefined: fmt", "--follow-up")
+	if err != nil {
+		t.Fatalf("explain --follow-up returned error: %v", err)
+	}
+	if !strings.HasPrefix(out, "The name fmt is not defined") || !strings.Contains(out, "fmt.Println(\"hi\")") {
+		t.Errorf("output = %q, want the explanation then the example", out)
+	}
+
+	// The chat was saved and can be picked up again
+	out, err = runCLIWith(t, env, "chat", "--list")
+	if err != nil {
+		t.Fatalf("chat --list returned error: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+	if len(lines) != 2 || !strings.Contains(lines[1], "undefined: fmt") {
+		t.Fatalf("chat --list = %q, want one saved chat", out)
+	}
+	id := strings.Fields(lines[1])[0]
+
+	rootCmd.SetIn(strings.NewReader("why?\n"))
+	out, err = runCLIWith(t, env, "chat", "--resume", id)
+	if err != nil {
+		t.Fatalf("chat --resume returned error: %v", err)
+	}
+	if !strings.Contains(out, "Back to: undefined: fmt") || !strings.Contains(out, "Go does not import anything for you.") {
+		t.Errorf("output = %q, want the last answer then the reply", out)
+	}
+}
+
+func TestConfigShowTaskModels(t *testing.T) {
+	env := map[string]string{
+		"GURUUI_AI_MODEL":            "gpt-4o",
+		"GURUUI_AI_MODELS_TRANSLATE": "gpt-4o-mini",
+	}
+
+	out, err := runCLIWith(t, env, "config", "show")
+	if err != nil {
+		t.Fatalf("config show returned error: %v", err)
+	}
+	for _, want := range []string{"explain:   gpt-4o (ai.model)", "translate: gpt-4o-mini", "chat:      gpt-4o (ai.model)"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("config show = %q, want it to contain %q", out, want)
+		}
+	}
+
+	// --model wins for every task
+	out, err = runCLIWith(t, env, "config", "show", "--model", "o3")
+	if err != nil {
+		t.Fatalf("config show --model returned error: %v", err)
+	}
+	if !strings.Contains(out, "translate: o3 (--model)") {
+		t.Errorf("config show --model = %q, want translate to use o3", out)
+	}
+
+	// With nothing set, the provider's own default is shown by name
+	out, err = runCLIWith(t, nil, "config", "show")
+	if err != nil {
+		t.Fatalf("config show returned error: %v", err)
+	}
+	if !strings.Contains(out, "explain:   gpt-4 (default)") {
+		t.Errorf("config show = %q, want explain to use gpt-4 (default)", out)
+	}
+}
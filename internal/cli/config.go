@@ -2,7 +2,10 @@ package cli
 
 import (
 	"fmt"
+	"io"
+	"strings"
 
+	"github.com/arnislvdev/go-guru-ui/internal/infrastructure/ai"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 )
@@ -25,6 +28,7 @@ var configSetCmd = &cobra.Command{
 	
 Examples:
   guruui config set --ai-provider openai --api-key <your-key>
+  guruui config set --ai-provider openai,anthropic,ollama
   guruui config set --default-mode wtf`,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		aiProvider, _ := cmd.Flags().GetString("ai-provider")
@@ -32,7 +36,20 @@ Examples:
 		defaultMode, _ := cmd.Flags().GetString("default-mode")
 
 		if aiProvider != "" {
-			viper.Set("ai.provider", aiProvider)
+			// A comma-separated list sets up a fallback chain
+			var providers []string
+			for _, name := range strings.Split(aiProvider, ",") {
+				name = strings.TrimSpace(name)
+				if !isKnownProvider(name) {
+					return fmt.Errorf("unknown AI provider %q (valid providers: %s)", name, strings.Join(ai.Providers(), ", "))
+				}
+				providers = append(providers, name)
+			}
+			if len(providers) == 1 {
+				viper.Set("ai.provider", providers[0])
+			} else {
+				viper.Set("ai.provider", providers)
+			}
 		}
 		if apiKey != "" {
 			viper.Set("ai.api_key", apiKey)
@@ -55,10 +72,15 @@ var configShowCmd = &cobra.Command{
 	Use:   "show",
 	Short: "Show current settings",
 	RunE: func(cmd *cobra.Command, args []string) error {
-		fmt.Println("Current Settings:")
-		fmt.Printf("AI Provider: %s\n", viper.GetString("ai.provider"))
-		fmt.Printf("Default Mode: %s\n", viper.GetString("default_mode"))
-		fmt.Printf("Settings File: %s\n", viper.ConfigFileUsed())
+		out := cmd.OutOrStdout()
+		fmt.Fprintln(out, "Current Settings:")
+		fmt.Fprintf(out, "AI Provider: %s\n", strings.Join(providerNames(), " -> "))
+		if err := showTaskModels(cmd, out); err != nil {
+			return err
+		}
+		fmt.Fprintf(out, "Max Tokens: %d\n", viper.GetInt("ai.max_tokens"))
+		fmt.Fprintf(out, "Default Mode: %s\n", viper.GetString("default_mode"))
+		fmt.Fprintf(out, "Settings File: %s\n", viper.ConfigFileUsed())
 		return nil
 	},
 }
@@ -85,7 +107,49 @@ func init() {
 	configCmd.AddCommand(configShowCmd)
 	configCmd.AddCommand(configResetCmd)
 
-	configSetCmd.Flags().String("ai-provider", "", "AI provider (openai, anthropic)")
+	configSetCmd.Flags().String("ai-provider", "", "AI provider ("+strings.Join(ai.Providers(), ", ")+"); a comma-separated list is tried in order")
 	configSetCmd.Flags().String("api-key", "", "API key for AI provider")
 	configSetCmd.Flags().String("default-mode", "", "default output mode (professional, wtf)")
 }
+
+// isKnownProvider reports whether name is a registered AI provider
+func isKnownProvider(name string) bool {
+	for _, provider := range ai.Providers() {
+		if provider == name {
+			return true
+		}
+	}
+	return false
+}
+
+// showTaskModels prints the model each task uses with each provider
+func showTaskModels(cmd *cobra.Command, out io.Writer) error {
+	providers := providerNames()
+	for i, provider := range providers {
+		cfg, err := loadAIConfig(cmd, provider, i == 0)
+		if err != nil {
+			return err
+		}
+
+		if len(providers) > 1 {
+			fmt.Fprintf(out, "AI Models (%s):\n", provider)
+		} else {
+			fmt.Fprintln(out, "AI Models:")
+		}
+		for _, task := range ai.Tasks() {
+			model := cfg.ModelFor(task)
+			switch {
+			case model == "" && ai.DefaultModel(provider) == "":
+				model = "provider default"
+			case model == "":
+				model = ai.DefaultModel(provider) + " (default)"
+			case cfg.Models[task] == "" && i == 0 && cmd.Flags().Changed("model"):
+				model += " (--model)"
+			case cfg.Models[task] == "":
+				model += " (ai.model)"
+			}
+			fmt.Fprintf(out, "  %-10s %s\n", task+":", model)
+		}
+	}
+	return nil
+}
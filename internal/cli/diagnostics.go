@@ -0,0 +1,133 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/arnislvdev/go-guru-ui/internal/domain"
+	"github.com/arnislvdev/go-guru-ui/internal/infrastructure/ai"
+	"github.com/arnislvdev/go-guru-ui/internal/parser"
+	"github.com/arnislvdev/go-guru-ui/internal/usecase"
+	"github.com/spf13/cobra"
+)
+
+// maxInputSize caps how much compiler or linter output is read
+const maxInputSize = 4 << 20
+
+// readInput reads path, or standard input when path is "-"
+func readInput(cmd *cobra.Command, path string) (string, error) {
+	var r io.Reader
+	if path == "-" {
+		r = cmd.InOrStdin()
+	} else {
+		f, err := os.Open(path)
+		if err != nil {
+			return "", fmt.Errorf("failed to open input: %w", err)
+		}
+		defer f.Close()
+		r = f
+	}
+
+	data, err := io.ReadAll(io.LimitReader(r, maxInputSize))
+	if err != nil {
+		return "", fmt.Errorf("failed to read input: %w", err)
+	}
+	return string(data), nil
+}
+
+// explainDiagnostics explains every diagnostic in go build, go vet, tsc or
+// ESLint output, grouped by file and in the order they appear in it
+func explainDiagnostics(cmd *cobra.Command, client ai.Client, opts []usecase.Option, input string) error {
+	output := parser.Diagnostics(input)
+	if len(output.Errors) == 0 {
+		return errors.New("no Go compiler, vet, tsc or ESLint diagnostics found in the input")
+	}
+	parser.SortByPosition(output.Errors)
+
+	ctx, meter := meterUsage(cmd)
+	defer recordUsage(cmd, meter)
+
+	explainer := usecase.NewErrorExplainer(client, opts...)
+	explainer.OnRedact = reportRedaction
+	explainer.OnToolCall = reportToolCall
+
+	out := cmd.OutOrStdout()
+	file := "\x00" // no file heading printed yet
+	for i, diagnostic := range output.Errors {
+		if diagnostic.File != file {
+			if i > 0 {
+				fmt.Fprintln(out)
+			}
+			file = diagnostic.File
+			heading := file
+			if heading == "" {
+				heading = "Other"
+			}
+			fmt.Fprintln(out, heading)
+		}
+		fmt.Fprintf(out, "  %s\n\n", describeDiagnostic(diagnostic))
+
+		err := explainer.ExplainErrorStream(ctx, diagnostic, mode, func(chunk string) {
+			fmt.Fprint(out, chunk)
+		})
+		if err != nil {
+			return aiFailure("failed to explain error", err)
+		}
+		fmt.Fprintln(out)
+		if i < len(output.Errors)-1 && output.Errors[i+1].File == file {
+			fmt.Fprintln(out)
+		}
+	}
+
+	if output.Truncated {
+		fmt.Fprintln(out, "\nThe compiler stopped after too many errors; fix these and build again to see the rest.")
+	}
+	reportProvider(client)
+	return nil
+}
+
+// explainGoroutineDump prints a summary of a goroutine dump and explains it.
+// Only the summary goes to the AI; a dump can hold thousands of goroutines.
+func explainGoroutineDump(cmd *cobra.Command, client ai.Client, opts []usecase.Option, text string) error {
+	dump := parser.GoroutineDump(text)
+	if len(dump.Goroutines) == 0 {
+		return errors.New("no goroutines found in the input")
+	}
+	report := usecase.AnalyzeGoroutines(dump)
+
+	out := cmd.OutOrStdout()
+	fmt.Fprintf(out, "%s\n\n", report.Summary())
+
+	ctx, meter := meterUsage(cmd)
+	defer recordUsage(cmd, meter)
+
+	explainer := usecase.NewErrorExplainer(client, opts...)
+	explainer.OnRedact = reportRedaction
+	explainer.OnToolCall = reportToolCall
+
+	err := explainer.ExplainGoroutines(ctx, report, mode, func(chunk string) {
+		fmt.Fprint(out, chunk)
+	})
+	if err != nil {
+		return aiFailure("failed to explain goroutine dump", err)
+	}
+	fmt.Fprintln(out)
+	reportProvider(client)
+	return nil
+}
+
+// describeDiagnostic shows where a diagnostic is and what it says, with
+// any extra lines of the message indented below it
+func describeDiagnostic(err *domain.Error) string {
+	var position string
+	switch {
+	case err.Line > 0 && err.Column > 0:
+		position = fmt.Sprintf("%d:%d: ", err.Line, err.Column)
+	case err.Line > 0:
+		position = fmt.Sprintf("%d: ", err.Line)
+	}
+	return position + strings.ReplaceAll(err.Message, "\n", "\n    ")
+}
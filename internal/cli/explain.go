@@ -2,39 +2,138 @@ package cli
 
 import (
 	"fmt"
+	"strings"
 
+	"github.com/arnislvdev/go-guru-ui/internal/parser"
 	"github.com/arnislvdev/go-guru-ui/internal/usecase"
 	"github.com/spf13/cobra"
 )
 
 var explainCmd = &cobra.Command{
-	Use:   "explain [error_message]",
+	Use:   "explain [error_message | -]",
 	Short: "Explain an error message in simple English",
 	Long: `Explain a programming error in clear, simple terms.
 	
 Examples:
   guruui explain "undefined: fmt"
   guruui explain "cannot use nil as type string in assignment"
-  guruui explain --file main.go --line 42`,
-	Args: cobra.ExactArgs(1),
+  guruui explain --file main.go --line 42
+  guruui explain "undefined: fmt" --follow-up
+  guruui explain "undefined: Load" -f store.go -l 12 --investigate
+  go build ./... 2>&1 | guruui explain -
+  guruui explain --input vet.log
+  go run . 2>&1 | guruui explain -          # a panic and its stack
+  python app.py 2>&1 | guruui explain -     # a Python traceback
+  node server.js 2>&1 | guruui explain -    # an uncaught Node.js exception
+  npx tsc --noEmit | guruui explain -
+  npx eslint src | guruui explain -
+  guruui explain --goroutines --input dump.txt`,
+	Args: cobra.MaximumNArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
-		errorMsg := args[0]
+		input, _ := cmd.Flags().GetString("input")
+		goroutines, _ := cmd.Flags().GetBool("goroutines")
+		var errorMsg string
+		switch {
+		case len(args) == 1 && args[0] == "-" && input == "":
+			input = "-"
+		case len(args) == 1 && input != "":
+			return fmt.Errorf("give an error message or --input, not both")
+		case len(args) == 1 && goroutines:
+			return fmt.Errorf("--goroutines reads the dump from --input or standard input, not an argument")
+		case len(args) == 1:
+			errorMsg = args[0]
+		case goroutines && input == "":
+			input = "-"
+		case input == "":
+			return fmt.Errorf("give an error message, or - to read compiler output from standard input")
+		}
+
+		// A panic, exception or traceback is one error with its stack; anything else is read as build or lint output
+		var diagnostics, dump string
+		if input != "" {
+			text, err := readInput(cmd, input)
+			if err != nil {
+				return err
+			}
+			if strings.TrimSpace(text) == "" {
+				return fmt.Errorf("the input is empty")
+			}
+			switch _, isPanic := parser.Parse(text); {
+			case goroutines:
+				dump = text
+			case isPanic:
+				errorMsg = text
+			default:
+				diagnostics = text
+			}
+		}
+		if followUp, _ := cmd.Flags().GetBool("follow-up"); followUp && errorMsg == "" {
+			return fmt.Errorf("--follow-up works with a single error or panic, not build output or goroutine dumps")
+		}
 
 		// Get the file and line info
 		file, _ := cmd.Flags().GetString("file")
 		line, _ := cmd.Flags().GetInt("line")
 
+		// Pick the AI provider from settings
+		client, err := newAIClient(cmd)
+		if err != nil {
+			return err
+		}
+
+		opts, err := redactOptions()
+		if err != nil {
+			return err
+		}
+		toolOpts, err := toolOptions(cmd, client)
+		if err != nil {
+			return err
+		}
+
+		// Every diagnostic in go build or go vet output, or a whole goroutine dump
+		if diagnostics != "" {
+			return explainDiagnostics(cmd, client, append(opts, toolOpts...), diagnostics)
+		}
+		if dump != "" {
+			return explainGoroutineDump(cmd, client, append(opts, toolOpts...), dump)
+		}
+
+		// Keep talking about it, as 'guruui chat' does
+		if followUp, _ := cmd.Flags().GetBool("follow-up"); followUp {
+			store, err := loadChatStore()
+			if err != nil {
+				return err
+			}
+			chatter := usecase.NewErrorChat(client, opts...)
+			chatter.OnRedact = reportRedaction
+
+			conv, err := explainForChat(cmd, client, chatter, append(opts, toolOpts...), errorMsg, file, line)
+			if err != nil {
+				return err
+			}
+			return runChat(cmd, client, chatter, store, conv)
+		}
+
+		// Count the tokens this run uses
+		ctx, meter := meterUsage(cmd)
+		defer recordUsage(cmd, meter)
+
 		// Make the error explainer
-		explainer := usecase.NewErrorExplainer()
+		explainer := usecase.NewErrorExplainer(client, append(opts, toolOpts...)...)
+		explainer.OnRedact = reportRedaction
+		explainer.OnToolCall = reportToolCall
 
-		// Get the explanation
-		explanation, err := explainer.Explain(errorMsg, file, line, mode)
+		// Show the explanation as the AI writes it
+		out := cmd.OutOrStdout()
+		err = explainer.ExplainStream(ctx, errorMsg, file, line, mode, func(chunk string) {
+			fmt.Fprint(out, chunk)
+		})
 		if err != nil {
-			return fmt.Errorf("failed to explain error: %w", err)
+			return aiFailure("failed to explain error", err)
 		}
 
-		// Show the explanation
-		fmt.Println(explanation)
+		fmt.Fprintln(out)
+		reportProvider(client)
 		return nil
 	},
 }
@@ -42,4 +141,8 @@ Examples:
 func init() {
 	explainCmd.Flags().StringP("file", "f", "", "source file path for context")
 	explainCmd.Flags().IntP("line", "l", 0, "line number for context")
+	explainCmd.Flags().Bool("follow-up", false, "ask follow-up questions after the explanation")
+	explainCmd.Flags().StringP("input", "i", "", "explain go build/vet, tsc or ESLint output, a panic, or a Python or Node.js stack from this file ('-' for standard input)")
+	explainCmd.Flags().Bool("goroutines", false, "summarize a goroutine dump (from --input or standard input) and explain why it hangs")
+	explainCmd.Flags().Bool("investigate", false, "let the AI read your code (read-only) before explaining")
 }
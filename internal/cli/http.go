@@ -0,0 +1,85 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/arnislvdev/go-guru-ui/internal/infrastructure/ai"
+	"github.com/spf13/viper"
+)
+
+// httpSetting reads one HTTP setting for provider. ai.<provider>.http.<key>
+// wins; unlike other provider settings, the shared ai.http.<key> applies to
+// every provider, since the proxy and certificates belong to the network.
+func httpSetting(provider, key string) string {
+	if value := viper.GetString("ai." + provider + ".http." + key); value != "" {
+		return value
+	}
+	return viper.GetString("ai.http." + key)
+}
+
+// loadHTTPConfig reads ai.http.* and ai.<provider>.http.* for provider
+func loadHTTPConfig(provider string) (ai.HTTPConfig, error) {
+	h := ai.HTTPConfig{
+		Proxy:    httpSetting(provider, "proxy"),
+		CAFile:   httpSetting(provider, "ca_file"),
+		CertFile: httpSetting(provider, "cert_file"),
+		KeyFile:  httpSetting(provider, "key_file"),
+	}
+
+	h.NoProxy = stringList("ai." + provider + ".http.no_proxy")
+	if len(h.NoProxy) == 0 {
+		h.NoProxy = stringList("ai.http.no_proxy")
+	}
+
+	timeouts := []struct {
+		key   string
+		value *time.Duration
+	}{
+		{"connect_timeout", &h.ConnectTimeout},
+		{"read_timeout", &h.ReadTimeout},
+	}
+	for _, t := range timeouts {
+		raw := httpSetting(provider, t.key)
+		if raw == "" {
+			continue
+		}
+		d, err := parseTimeout(raw)
+		if err != nil {
+			return h, fmt.Errorf("invalid ai.http.%s: %w", t.key, err)
+		}
+		*t.value = d
+	}
+
+	return h, nil
+}
+
+// stringList reads key as a list, or as a comma-separated string (handy in
+// GURUUI_* variables)
+func stringList(key string) []string {
+	var raw []string
+	switch value := viper.Get(key).(type) {
+	case nil:
+	case string:
+		raw = strings.Split(value, ",")
+	default:
+		raw = viper.GetStringSlice(key)
+	}
+
+	var list []string
+	for _, item := range raw {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+	return list
+}
+
+// reportHTTP tells verbose users how requests to provider will travel
+func reportHTTP(provider string, h ai.HTTPConfig) {
+	if verbose {
+		fmt.Fprintf(os.Stderr, "HTTP for %s: %s\n", provider, h.Describe())
+	}
+}
@@ -0,0 +1,210 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/arnislvdev/go-guru-ui/internal/infrastructure/ai"
+	"github.com/arnislvdev/go-guru-ui/internal/usecase"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var promptsCmd = &cobra.Command{
+	Use:   "prompts",
+	Short: "See and change what GuruUI asks the AI",
+	Long: `GuruUI builds its AI requests from templates. Put a file with the same name
+in your prompts folder (prompts.dir) to use your own instead of the built-in one.
+
+Templates: ` + strings.Join(ai.PromptNames(), ", ") + `
+
+Examples:
+  guruui prompts show explain
+  guruui prompts edit explain
+  guruui prompts test explain --error "undefined: fmt"`,
+}
+
+var promptsShowCmd = &cobra.Command{
+	Use:   "show [template...]",
+	Short: "Show the templates in use and where they come from",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		prompts, err := loadPrompts()
+		if err != nil {
+			return err
+		}
+
+		names, err := promptNames(args)
+		if err != nil {
+			return err
+		}
+		for i, name := range names {
+			text, origin, _ := prompts.Text(name)
+			if i > 0 {
+				fmt.Println()
+			}
+			fmt.Printf("== %s (%s) ==\n", name, origin)
+			fmt.Println(strings.TrimRight(text, "\n"))
+		}
+		return nil
+	},
+}
+
+var promptsEditCmd = &cobra.Command{
+	Use:   "edit <template>",
+	Short: "Open a template in your editor, starting from the built-in one",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if _, err := promptNames(args); err != nil {
+			return err
+		}
+
+		dir, err := promptsDir()
+		if err != nil {
+			return err
+		}
+		path := filepath.Join(dir, args[0]+".tmpl")
+
+		// Start from the built-in text the first time
+		if _, err := os.Stat(path); errors.Is(err, os.ErrNotExist) {
+			if err := os.MkdirAll(dir, 0o700); err != nil {
+				return fmt.Errorf("failed to create prompts directory: %w", err)
+			}
+			text, _, _ := ai.DefaultPrompts().Text(args[0])
+			if err := os.WriteFile(path, []byte(text), 0o600); err != nil {
+				return fmt.Errorf("failed to write template: %w", err)
+			}
+		}
+
+		if err := runEditor(path); err != nil {
+			return err
+		}
+
+		// Check it now rather than on the next request
+		if _, err := ai.LoadPrompts(dir); err != nil {
+			return fmt.Errorf("saved, but the template doesn't work yet: %w", err)
+		}
+		fmt.Printf("Saved %s\n", path)
+		return nil
+	},
+}
+
+var promptsTestCmd = &cobra.Command{
+	Use:   "test [template...]",
+	Short: "Show templates filled in with sample or given input",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		errorMsg, _ := cmd.Flags().GetString("error")
+		file, _ := cmd.Flags().GetString("file")
+		line, _ := cmd.Flags().GetInt("line")
+		query, _ := cmd.Flags().GetString("query")
+		contextInfo, _ := cmd.Flags().GetString("context")
+
+		prompts, err := loadPrompts()
+		if err != nil {
+			return err
+		}
+
+		names, err := promptNames(args)
+		if err != nil {
+			return err
+		}
+		for i, name := range names {
+			data := ai.SamplePromptData(name)
+			switch {
+			case strings.HasPrefix(name, "explain") && errorMsg != "":
+				data = usecase.NewErrorExplainer(nil).Parse(errorMsg, file, line)
+			case strings.HasPrefix(name, "translate") && query != "":
+				data = ai.NewTranslationInput(query, contextInfo)
+			}
+
+			rendered, err := prompts.Render(name, data)
+			if err != nil {
+				return err
+			}
+			if i > 0 {
+				fmt.Println()
+			}
+			fmt.Printf("== %s ==\n%s\n", name, rendered)
+		}
+		return nil
+	},
+}
+
+func init() {
+	promptsCmd.AddCommand(promptsShowCmd)
+	promptsCmd.AddCommand(promptsEditCmd)
+	promptsCmd.AddCommand(promptsTestCmd)
+
+	promptsTestCmd.Flags().String("error", "", "error message to fill the explain templates with")
+	promptsTestCmd.Flags().StringP("file", "f", "", "source file for --error")
+	promptsTestCmd.Flags().IntP("line", "l", 0, "line number for --error")
+	promptsTestCmd.Flags().String("query", "", "request to fill the translate templates with")
+	promptsTestCmd.Flags().StringP("context", "c", "", "context for --query")
+}
+
+// promptsDir returns prompts.dir, or guruui/prompts in the user config folder
+func promptsDir() (string, error) {
+	if dir := viper.GetString("prompts.dir"); dir != "" {
+		return dir, nil
+	}
+
+	base, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to find config directory: %w", err)
+	}
+	return filepath.Join(base, "guruui", "prompts"), nil
+}
+
+// loadPrompts reads the prompt templates, with the user's overrides
+func loadPrompts() (*ai.Prompts, error) {
+	dir, err := promptsDir()
+	if err != nil {
+		return nil, err
+	}
+	return ai.LoadPrompts(dir)
+}
+
+// promptNames checks the template names given on the command line; none means all of them
+func promptNames(args []string) ([]string, error) {
+	if len(args) == 0 {
+		return ai.PromptNames(), nil
+	}
+
+	known := make(map[string]bool)
+	for _, name := range ai.PromptNames() {
+		known[name] = true
+	}
+	for _, name := range args {
+		if !known[name] {
+			return nil, fmt.Errorf("unknown prompt template %q (valid names: %s)", name, strings.Join(ai.PromptNames(), ", "))
+		}
+	}
+	return args, nil
+}
+
+// runEditor opens path in $VISUAL or $EDITOR and waits for it to close
+func runEditor(path string) error {
+	editor := os.Getenv("VISUAL")
+	if editor == "" {
+		editor = os.Getenv("EDITOR")
+	}
+	if editor == "" {
+		editor = "vi"
+		if runtime.GOOS == "windows" {
+			editor = "notepad"
+		}
+	}
+
+	// The editor setting may carry its own arguments, e.g. "code --wait"
+	parts := strings.Fields(editor)
+	editCmd := exec.Command(parts[0], append(parts[1:], path)...)
+	editCmd.Stdin, editCmd.Stdout, editCmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+	if err := editCmd.Run(); err != nil {
+		return fmt.Errorf("failed to run editor %q: %w", editor, err)
+	}
+	return nil
+}
@@ -0,0 +1,34 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/arnislvdev/go-guru-ui/internal/usecase"
+	"github.com/arnislvdev/go-guru-ui/pkg/redact"
+	"github.com/spf13/viper"
+)
+
+func init() {
+	viper.SetDefault("redact.enabled", true)
+}
+
+// redactOptions returns the use case options for redact.enabled and redact.patterns
+func redactOptions() ([]usecase.Option, error) {
+	if !viper.GetBool("redact.enabled") {
+		return nil, nil
+	}
+
+	redactor, err := redact.New(viper.GetStringSlice("redact.patterns"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid redact settings: %w", err)
+	}
+	return []usecase.Option{usecase.WithRedactor(redactor)}, nil
+}
+
+// reportRedaction says in verbose mode how many values were hidden from the AI
+func reportRedaction(count int) {
+	if verbose && count > 0 {
+		fmt.Fprintf(os.Stderr, "Hid %d private value(s) from the AI; they are put back in the answer\n", count)
+	}
+}
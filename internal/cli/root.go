@@ -1,17 +1,26 @@
 package cli
 
 import (
+	"context"
 	"fmt"
 	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
 
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 )
 
 var (
-	cfgFile string
-	mode    string
-	verbose bool
+	cfgFile       string
+	mode          string
+	verbose       bool
+	modelFlag     string
+	maxTokensFlag int
+	timeoutFlag   time.Duration
+	noCache       bool
 )
 
 // This is the main command - what runs when you just type 'guruui'
@@ -32,11 +41,18 @@ Examples:
   guruui translate "how do I check disk space"
   guruui --mode wtf explain "segmentation fault"`,
 	Version: "0.1.0",
+	// main prints the error, and usage text hides it for runtime failures
+	SilenceErrors: true,
+	SilenceUsage:  true,
 }
 
-// Execute runs the main command and adds all the smaller commands
+// Execute runs the main command and adds all the smaller commands.
+// Ctrl-C or SIGTERM cancels whatever AI request is running.
 func Execute() error {
-	return rootCmd.Execute()
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	return rootCmd.ExecuteContext(ctx)
 }
 
 func init() {
@@ -46,11 +62,19 @@ func init() {
 	rootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", "config file (default is $HOME/.guruui.yaml)")
 	rootCmd.PersistentFlags().StringVarP(&mode, "mode", "m", "professional", "output mode: professional or wtf")
 	rootCmd.PersistentFlags().BoolVarP(&verbose, "verbose", "v", false, "verbose output")
+	rootCmd.PersistentFlags().StringVar(&modelFlag, "model", "", "AI model to use for this run (overrides ai.model and ai.models)")
+	rootCmd.PersistentFlags().IntVar(&maxTokensFlag, "max-tokens", 0, "longest AI response for this run (overrides ai.max_tokens)")
+	rootCmd.PersistentFlags().DurationVar(&timeoutFlag, "timeout", 0, "how long to wait for the AI, e.g. 30s (overrides ai.timeout)")
+	rootCmd.PersistentFlags().BoolVar(&noCache, "no-cache", false, "ask the AI even if a cached answer exists")
 
 	// Add subcommands
 	rootCmd.AddCommand(explainCmd)
 	rootCmd.AddCommand(translateCmd)
+	rootCmd.AddCommand(chatCmd)
 	rootCmd.AddCommand(configCmd)
+	rootCmd.AddCommand(cacheCmd)
+	rootCmd.AddCommand(usageCmd)
+	rootCmd.AddCommand(promptsCmd)
 }
 
 // initConfig reads the settings file and environment variables
@@ -72,7 +96,10 @@ func initConfig() {
 		viper.SetConfigName(".guruui")
 	}
 
-	viper.AutomaticEnv() // read environment variables
+	// Read GURUUI_* environment variables, e.g. GURUUI_AI_API_KEY for ai.api_key
+	viper.SetEnvPrefix("guruui")
+	viper.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
+	viper.AutomaticEnv()
 
 	// If we found a settings file, read it
 	if err := viper.ReadInConfig(); err == nil {
@@ -0,0 +1,78 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/arnislvdev/go-guru-ui/internal/infrastructure/ai"
+	"github.com/arnislvdev/go-guru-ui/internal/infrastructure/tools"
+	"github.com/arnislvdev/go-guru-ui/internal/usecase"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+func init() {
+	viper.SetDefault("tools.enabled", true)
+	viper.SetDefault("tools.max_steps", 5)
+}
+
+// toolOptions returns the use case options that let the AI look at the
+// project, when --investigate is set and tools.enabled allows it
+func toolOptions(cmd *cobra.Command, client ai.Client) ([]usecase.Option, error) {
+	if investigate, _ := cmd.Flags().GetBool("investigate"); !investigate {
+		return nil, nil
+	}
+	if !viper.GetBool("tools.enabled") {
+		return nil, fmt.Errorf("--investigate needs tools, but tools.enabled is false")
+	}
+	if client.GetProvider() == ai.ProviderOffline {
+		if verbose {
+			fmt.Fprintln(os.Stderr, "The offline explainer can't use tools; answering without them")
+		}
+		return nil, nil
+	}
+
+	maxSteps := viper.GetInt("tools.max_steps")
+	if maxSteps < 1 {
+		return nil, fmt.Errorf("tools.max_steps must be at least 1, got %d", maxSteps)
+	}
+
+	sandbox, err := tools.NewSandbox(projectRoot())
+	if err != nil {
+		return nil, err
+	}
+	list := tools.Select(sandbox.Tools(), viper.GetStringSlice("tools.disabled"))
+	if len(list) == 0 {
+		return nil, fmt.Errorf("--investigate needs tools, but tools.disabled turns them all off")
+	}
+	return []usecase.Option{usecase.WithTools(list, maxSteps)}, nil
+}
+
+// reportToolCall shows each tool the AI runs in verbose mode
+func reportToolCall(call usecase.ToolCall, err error) {
+	if !verbose {
+		return
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Tool: %s (failed: %v)\n", call, err)
+		return
+	}
+	fmt.Fprintf(os.Stderr, "Tool: %s\n", call)
+}
+
+// projectRoot returns the nearest folder with a go.mod, or the working folder
+func projectRoot() string {
+	wd, err := os.Getwd()
+	if err != nil {
+		return "."
+	}
+	for dir := wd; ; dir = filepath.Dir(dir) {
+		if _, err := os.Stat(filepath.Join(dir, "go.mod")); err == nil {
+			return dir
+		}
+		if filepath.Dir(dir) == dir {
+			return wd
+		}
+	}
+}
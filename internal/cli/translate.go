@@ -2,6 +2,7 @@ package cli
 
 import (
 	"fmt"
+	"sort"
 
 	"github.com/arnislvdev/go-guru-ui/internal/usecase"
 	"github.com/spf13/cobra"
@@ -23,18 +24,43 @@ Examples:
 		// Get extra info
 		context, _ := cmd.Flags().GetString("context")
 
+		// Pick the AI provider from settings
+		client, err := newAIClient(cmd)
+		if err != nil {
+			return err
+		}
+
+		opts, err := redactOptions()
+		if err != nil {
+			return err
+		}
+
+		// Count the tokens this run uses
+		ctx, meter := meterUsage(cmd)
+		defer recordUsage(cmd, meter)
+
 		// Make the translator
-		translator := usecase.NewCommandTranslator()
+		translator := usecase.NewCommandTranslator(client, opts...)
+		translator.OnRedact = reportRedaction
 
 		// Turn words into command
-		command, explanation, err := translator.Translate(query, context, mode)
+		command, err := translator.Translate(ctx, query, context, mode)
 		if err != nil {
-			return fmt.Errorf("failed to translate query: %w", err)
+			return aiFailure("failed to translate query", err)
 		}
+		reportProvider(client)
 
 		// Show the result
-		fmt.Printf("Command: %s\n\n", command)
-		fmt.Printf("Explanation: %s\n", explanation)
+		out := cmd.OutOrStdout()
+		fmt.Fprintf(out, "Command: %s\n\n", command.Command)
+		if len(command.Flags) > 0 {
+			fmt.Fprintln(out, "Flags:")
+			for _, flag := range sortedKeys(command.Flags) {
+				fmt.Fprintf(out, "  %s  %s\n", flag, command.Flags[flag])
+			}
+			fmt.Fprintln(out)
+		}
+		fmt.Fprintf(out, "Explanation: %s\n", command.Explanation)
 		return nil
 	},
 }
@@ -42,3 +68,13 @@ Examples:
 func init() {
 	translateCmd.Flags().StringP("context", "c", "", "additional context (e.g., operating system, environment)")
 }
+
+// sortedKeys returns a map's keys in order, so flags print the same way every time
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,160 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/arnislvdev/go-guru-ui/internal/infrastructure/ai"
+	"github.com/arnislvdev/go-guru-ui/internal/infrastructure/usage"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var usageCmd = &cobra.Command{
+	Use:   "usage",
+	Short: "Show tokens used and what they cost",
+	Long: `Show how many tokens GuruUI has used and about what they cost, by day,
+command and model. Costs use list prices; add your own under usage.prices.
+
+Examples:
+  guruui usage
+  guruui usage --days 30`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		days, _ := cmd.Flags().GetInt("days")
+		if days < 1 {
+			return fmt.Errorf("--days must be at least 1, got %d", days)
+		}
+
+		store, err := loadUsageStore()
+		if err != nil {
+			return err
+		}
+
+		since := time.Now().AddDate(0, 0, -(days - 1))
+		rows, err := store.Rows(since)
+		if err != nil {
+			return err
+		}
+		if len(rows) == 0 {
+			fmt.Printf("No usage in the last %d days\n", days)
+			return nil
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "DAY\tCOMMAND\tMODEL\tREQUESTS\tINPUT\tOUTPUT\tCOST")
+
+		var total usage.Row
+		for _, row := range rows {
+			fmt.Fprintf(w, "%s\t%s\t%s/%s\t%d\t%d\t%d\t%s\n",
+				row.Day, row.Command, row.Provider, row.Model, row.Requests, row.InputTokens, row.OutputTokens, formatCost(row))
+
+			total.Requests += row.Requests
+			total.InputTokens += row.InputTokens
+			total.OutputTokens += row.OutputTokens
+			total.Cost += row.Cost
+			total.Unpriced += row.Unpriced
+			total.Estimated += row.Estimated
+		}
+		fmt.Fprintf(w, "Total\t\t\t%d\t%d\t%d\t%s\n", total.Requests, total.InputTokens, total.OutputTokens, formatCost(total))
+		if err := w.Flush(); err != nil {
+			return err
+		}
+
+		if total.Estimated > 0 {
+			fmt.Println("\n~ some token counts are estimates (the provider didn't report them)")
+		}
+		if total.Unpriced > 0 {
+			fmt.Println("+ some models have no price; add them under usage.prices")
+		}
+		return nil
+	},
+}
+
+func init() {
+	usageCmd.Flags().Int("days", 7, "how many days to show, counting today")
+}
+
+// meterUsage returns a context whose AI requests report their token usage to the returned meter
+func meterUsage(cmd *cobra.Command) (context.Context, *ai.UsageMeter) {
+	meter := &ai.UsageMeter{}
+	return ai.WithUsageMeter(cmd.Context(), meter), meter
+}
+
+// recordUsage shows the run's usage in verbose mode and adds it to the daily
+// totals. Keeping count must never fail the command, so problems are only
+// reported in verbose mode.
+func recordUsage(cmd *cobra.Command, meter *ai.UsageMeter) {
+	records := meter.Records()
+	if len(records) == 0 {
+		return
+	}
+
+	prices := loadPrices()
+	if verbose {
+		for _, record := range records {
+			cost := "unknown cost"
+			if c, ok := prices.Cost(record.Provider, record.Model, record.InputTokens, record.OutputTokens); ok {
+				cost = fmt.Sprintf("$%.4f", c)
+			}
+			estimate := ""
+			if record.Estimated {
+				estimate = " (estimated)"
+			}
+			fmt.Fprintf(os.Stderr, "Tokens for %s/%s: %d in, %d out%s, %s\n",
+				record.Provider, record.Model, record.InputTokens, record.OutputTokens, estimate, cost)
+		}
+	}
+
+	store, err := loadUsageStore()
+	if err == nil {
+		err = store.Add(time.Now(), cmd.Name(), records, prices)
+	}
+	if err != nil && verbose {
+		fmt.Fprintf(os.Stderr, "Could not save usage: %v\n", err)
+	}
+}
+
+// loadUsageStore opens the usage file at usage.file, or the default location
+func loadUsageStore() (*usage.Store, error) {
+	path := viper.GetString("usage.file")
+	if path == "" {
+		var err error
+		if path, err = usage.DefaultPath(); err != nil {
+			return nil, err
+		}
+	}
+	return usage.NewStore(path), nil
+}
+
+// loadPrices returns the built-in prices with usage.prices on top
+func loadPrices() usage.PriceTable {
+	prices := usage.DefaultPrices()
+
+	var custom map[string]usage.Price
+	if err := viper.UnmarshalKey("usage.prices", &custom); err != nil {
+		if verbose {
+			fmt.Fprintf(os.Stderr, "Ignoring usage.prices: %v\n", err)
+		}
+		return prices
+	}
+	for model, price := range custom {
+		prices[model] = price
+	}
+	return prices
+}
+
+// formatCost shows a row's cost, marked when part of it is estimated or unknown
+func formatCost(row usage.Row) string {
+	cost := fmt.Sprintf("$%.4f", row.Cost)
+	if row.Estimated > 0 {
+		cost = "~" + cost
+	}
+	if row.Unpriced > 0 {
+		cost += "+"
+	}
+	return cost
+}
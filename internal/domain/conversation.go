@@ -0,0 +1,26 @@
+package domain
+
+import "time"
+
+// Message roles
+const (
+	RoleUser      = "user"
+	RoleAssistant = "assistant"
+)
+
+// Message is one turn of a follow-up conversation
+type Message struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+// Conversation is a follow-up chat about an error: the error, the first
+// explanation and every question and answer after it
+type Conversation struct {
+	ID       string    `json:"id"`
+	Error    *Error    `json:"error"`
+	Messages []Message `json:"messages"` // starts with the explanation, then alternates user and assistant
+	Provider string    `json:"provider,omitempty"`
+	Created  time.Time `json:"created"`
+	Updated  time.Time `json:"updated"`
+}
@@ -6,8 +6,30 @@ type Error struct {
 	Type     string `json:"type"`
 	File     string `json:"file,omitempty"`
 	Line     int    `json:"line,omitempty"`
+	Column   int    `json:"column,omitempty"`
+	Package  string `json:"package,omitempty"` // the package being built, from a "# pkg" header
+	Code     string `json:"code,omitempty"`    // the compiler's or linter's name for it, e.g. TS2345 or no-undef
 	Severity string `json:"severity"`
 	Language string `json:"language"`
+
+	// Set for exceptions, in languages that have them
+	Exception string   `json:"exception,omitempty"` // the exception's class, e.g. ValueError
+	Causes    []string `json:"causes,omitempty"`    // exceptions raised earlier in a chain, first one first
+
+	// Set for Go panics and fatal runtime errors
+	PanicValue  string `json:"panic_value,omitempty"`  // what was passed to panic, or the runtime's message
+	RuntimeKind string `json:"runtime_kind,omitempty"` // one of the RuntimeKind constants
+
+	// Stack is the failing goroutine's or thread's calls, innermost first
+	Stack []Frame `json:"stack,omitempty"`
+}
+
+// Frame is one call in a stack trace
+type Frame struct {
+	Function string `json:"function"`
+	File     string `json:"file,omitempty"`
+	Line     int    `json:"line,omitempty"`
+	User     bool   `json:"user,omitempty"` // in the program's own code rather than the runtime or standard library
 }
 
 // ErrorType constants
@@ -18,6 +40,16 @@ const (
 	ErrorTypeUnusedVariable  = "unused_variable"
 	ErrorTypeMissingReturn   = "missing_return"
 	ErrorTypeArgumentCount   = "argument_count"
+	ErrorTypeRuntimePanic    = "runtime_panic"
+	ErrorTypeGoroutineDump   = "goroutine_dump"
+	ErrorTypeSyntax          = "syntax_error"
+	ErrorTypeImport          = "import_error"
+	ErrorTypeNullReference   = "null_reference"
+	ErrorTypeLookup          = "lookup_error" // missing key or index
+	ErrorTypeValue           = "value_error"
+	ErrorTypeArithmetic      = "arithmetic_error"
+	ErrorTypeIO              = "io_error"
+	ErrorTypeResource        = "resource_error" // recursion or memory limits
 	ErrorTypeUnknown         = "unknown"
 )
 
@@ -34,6 +66,25 @@ const (
 	LanguageGo      = "go"
 	LanguagePython  = "python"
 	LanguageJS      = "javascript"
+	LanguageTS      = "typescript"
 	LanguageRust    = "rust"
 	LanguageUnknown = "unknown"
 )
+
+// RuntimeKind constants
+const (
+	RuntimeNilMapWrite     = "nil_map_write"
+	RuntimeIndexOutOfRange = "index_out_of_range"
+	RuntimeSliceBounds     = "slice_bounds_out_of_range"
+	RuntimeNilPointer      = "nil_pointer_dereference"
+	RuntimeClosedChannel   = "close_of_closed_channel"
+	RuntimeNilChannel      = "close_of_nil_channel"
+	RuntimeSendOnClosed    = "send_on_closed_channel"
+	RuntimeDivideByZero    = "integer_divide_by_zero"
+	RuntimeTypeAssertion   = "failed_type_assertion"
+	RuntimeConcurrentMap   = "concurrent_map_access"
+	RuntimeDeadlock        = "deadlock"
+	RuntimeStackOverflow   = "stack_overflow"
+	RuntimeOutOfMemory     = "out_of_memory"
+	RuntimeCustomPanic     = "custom_panic" // panic called by the program itself
+)
@@ -0,0 +1,300 @@
+package ai
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/arnislvdev/go-guru-ui/internal/domain"
+)
+
+const (
+	anthropicAPIURL       = "https://api.anthropic.com"
+	anthropicAPIVersion   = "2023-06-01"
+	defaultAnthropicModel = "claude-3-5-sonnet-latest"
+)
+
+// anthropicErrorStatus maps error types sent mid-stream to the HTTP status they stand for
+var anthropicErrorStatus = map[string]int{
+	"rate_limit_error": http.StatusTooManyRequests,
+	"api_error":        http.StatusInternalServerError,
+	"overloaded_error": 529,
+}
+
+// AnthropicClient implements the AI Client interface using the Anthropic Messages API
+type AnthropicClient struct {
+	httpClient *http.Client
+	config     *Config
+	baseURL    string
+	model      string
+}
+
+// anthropicRequest is the body sent to the Messages endpoint
+type anthropicRequest struct {
+	Model     string        `json:"model"`
+	MaxTokens int           `json:"max_tokens"`
+	System    string        `json:"system,omitempty"`
+	Messages  []chatMessage `json:"messages"`
+	Stream    bool          `json:"stream,omitempty"`
+}
+
+// anthropicResponse is the part of the Messages reply we use
+type anthropicResponse struct {
+	Content []struct {
+		Type string `json:"type"`
+		Text string `json:"text"`
+	} `json:"content"`
+	Usage anthropicUsage  `json:"usage"`
+	Error *anthropicError `json:"error,omitempty"`
+}
+
+// anthropicUsage is the token count in replies and stream events
+type anthropicUsage struct {
+	InputTokens  int `json:"input_tokens"`
+	OutputTokens int `json:"output_tokens"`
+}
+
+// anthropicError is the error object in failed replies and stream error events
+type anthropicError struct {
+	Type    string `json:"type"`
+	Message string `json:"message"`
+}
+
+// anthropicEvent is the part of a streamed Messages event we use
+type anthropicEvent struct {
+	Type  string `json:"type"`
+	Delta struct {
+		Type string `json:"type"`
+		Text string `json:"text"`
+	} `json:"delta"`
+	Message struct {
+		Usage anthropicUsage `json:"usage"`
+	} `json:"message"` // message_start
+	Usage anthropicUsage  `json:"usage"` // message_delta
+	Error *anthropicError `json:"error,omitempty"`
+}
+
+func init() {
+	Register("anthropic", func(cfg *Config) (Client, error) {
+		return NewAnthropicClient(cfg)
+	})
+}
+
+// NewAnthropicClient creates a new Anthropic client from the given config
+func NewAnthropicClient(cfg *Config) (*AnthropicClient, error) {
+	if cfg == nil {
+		cfg = DefaultConfig()
+	}
+	if cfg.APIKey == "" {
+		return nil, ErrMissingAPIKey
+	}
+
+	baseURL := cfg.BaseURL
+	if baseURL == "" {
+		baseURL = anthropicAPIURL
+	}
+
+	httpClient, err := newHTTPClient(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	return &AnthropicClient{
+		httpClient: httpClient,
+		config:     cfg,
+		baseURL:    strings.TrimRight(baseURL, "/"),
+		model:      cfg.modelOr(defaultAnthropicModel),
+	}, nil
+}
+
+// ExplainError explains a programming error using Anthropic
+func (c *AnthropicClient) ExplainError(ctx context.Context, err *domain.Error) (string, error) {
+	req, promptErr := c.config.prompts().explainRequest(err)
+	if promptErr != nil {
+		return "", promptErr
+	}
+	return c.complete(ctx, req)
+}
+
+// ExplainErrorStream explains a programming error, passing text to onChunk as it arrives
+func (c *AnthropicClient) ExplainErrorStream(ctx context.Context, err *domain.Error, onChunk func(string)) (string, error) {
+	req, promptErr := c.config.prompts().explainRequest(err)
+	if promptErr != nil {
+		return "", promptErr
+	}
+	req.OnChunk = onChunk
+	return c.complete(ctx, req)
+}
+
+// Chat answers a follow-up question about err using Anthropic
+func (c *AnthropicClient) Chat(ctx context.Context, err *domain.Error, messages []domain.Message) (string, error) {
+	req, promptErr := c.config.prompts().chatRequest(ctx, err, messages)
+	if promptErr != nil {
+		return "", promptErr
+	}
+	return c.complete(ctx, req)
+}
+
+// TranslateQuery converts natural language to CLI commands
+func (c *AnthropicClient) TranslateQuery(ctx context.Context, query, contextInfo string) (*domain.Command, error) {
+	return translateQuery(ctx, c, c.config.prompts(), query, contextInfo)
+}
+
+// GetProvider returns the provider name
+func (c *AnthropicClient) GetProvider() string {
+	return "anthropic"
+}
+
+// complete sends the conversation and returns the reply text. When req.OnChunk
+// is set the reply is streamed and each piece of text is passed to it as it arrives.
+// The Messages API has no JSON mode; translateQuery's parser copes without it.
+func (c *AnthropicClient) complete(ctx context.Context, req completion) (string, error) {
+	ctx, cancel := c.config.withTimeout(ctx)
+	defer cancel()
+
+	model := c.config.modelFor(req.Task, c.model)
+
+	body, err := json.Marshal(anthropicRequest{
+		Model:     model,
+		MaxTokens: c.config.MaxTokens,
+		System:    req.System,
+		Messages:  req.Messages,
+		Stream:    req.OnChunk != nil,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to encode Anthropic request: %w", err)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL+"/v1/messages", bytes.NewReader(body))
+	if err != nil {
+		return "", fmt.Errorf("failed to create Anthropic request: %w", err)
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	httpReq.Header.Set("x-api-key", c.config.APIKey)
+	httpReq.Header.Set("anthropic-version", anthropicAPIVersion)
+
+	resp, err := c.httpClient.Do(httpReq)
+	if err != nil {
+		if ctxErr := c.config.contextError(ctx, c.GetProvider()); ctxErr != nil {
+			return "", ctxErr
+		}
+		return "", fmt.Errorf("Anthropic API error: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var text string
+	var usage anthropicUsage
+	if resp.StatusCode == http.StatusOK && req.OnChunk != nil {
+		text, usage, err = c.readEvents(resp.Body, req.OnChunk)
+	} else {
+		text, usage, err = c.readMessage(resp)
+	}
+	if err != nil {
+		if ctxErr := c.config.contextError(ctx, c.GetProvider()); ctxErr != nil {
+			return "", ctxErr
+		}
+		return "", err
+	}
+
+	reportUsage(ctx, Usage{
+		Provider:     c.GetProvider(),
+		Model:        model,
+		InputTokens:  usage.InputTokens,
+		OutputTokens: usage.OutputTokens,
+	})
+
+	if text == "" {
+		return "", fmt.Errorf("no response from Anthropic")
+	}
+
+	return text, nil
+}
+
+// readMessage decodes a complete (non-streamed) Messages reply or error
+func (c *AnthropicClient) readMessage(resp *http.Response) (string, anthropicUsage, error) {
+	var result anthropicResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return "", result.Usage, &APIError{
+			Provider:   "Anthropic",
+			StatusCode: resp.StatusCode,
+			Message:    "failed to decode response",
+			RetryAfter: parseRetryAfter(resp.Header.Get("Retry-After")),
+			Err:        err,
+		}
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		apiErr := &APIError{
+			Provider:   "Anthropic",
+			StatusCode: resp.StatusCode,
+			Message:    http.StatusText(resp.StatusCode),
+			RetryAfter: parseRetryAfter(resp.Header.Get("Retry-After")),
+		}
+		if result.Error != nil {
+			apiErr.Message = result.Error.Type + ": " + result.Error.Message
+		}
+		return "", result.Usage, apiErr
+	}
+
+	var text strings.Builder
+	for _, block := range result.Content {
+		if block.Type == "text" {
+			text.WriteString(block.Text)
+		}
+	}
+	return text.String(), result.Usage, nil
+}
+
+// readEvents reads a server-sent event stream, passing each text delta to onChunk.
+// The input count comes at the start of the stream and the output count near the end.
+func (c *AnthropicClient) readEvents(r io.Reader, onChunk func(string)) (string, anthropicUsage, error) {
+	var text strings.Builder
+	var usage anthropicUsage
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+
+	for scanner.Scan() {
+		data, ok := strings.CutPrefix(scanner.Text(), "data:")
+		if !ok {
+			// "event:" lines repeat the type that is also in the data
+			continue
+		}
+
+		var event anthropicEvent
+		if err := json.Unmarshal([]byte(strings.TrimSpace(data)), &event); err != nil {
+			return "", usage, fmt.Errorf("Anthropic sent bad stream data: %w", err)
+		}
+
+		switch event.Type {
+		case "message_start":
+			usage = event.Message.Usage
+		case "message_delta":
+			usage.OutputTokens = event.Usage.OutputTokens
+		case "content_block_delta":
+			if event.Delta.Type == "text_delta" && event.Delta.Text != "" {
+				text.WriteString(event.Delta.Text)
+				onChunk(event.Delta.Text)
+			}
+		case "error":
+			apiErr := &APIError{Provider: "Anthropic", Message: "error in stream"}
+			if event.Error != nil {
+				apiErr.StatusCode = anthropicErrorStatus[event.Error.Type]
+				apiErr.Message = event.Error.Type + ": " + event.Error.Message
+			}
+			return "", usage, apiErr
+		case "message_stop":
+			return text.String(), usage, nil
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return "", usage, fmt.Errorf("failed to read Anthropic stream: %w", err)
+	}
+	return text.String(), usage, nil
+}
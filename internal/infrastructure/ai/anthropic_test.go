@@ -0,0 +1,197 @@
+package ai
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/arnislvdev/go-guru-ui/internal/domain"
+)
+
+// newAnthropicTestServer serves the Messages endpoint with a fixed reply and captures the request
+func newAnthropicTestServer(t *testing.T, status int, reply string, got *anthropicRequest) *httptest.Server {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/messages" {
+			t.Errorf("path = %s, want /v1/messages", r.URL.Path)
+		}
+		if key := r.Header.Get("x-api-key"); key != "test-key" {
+			t.Errorf("x-api-key = %q, want test-key", key)
+		}
+		if version := r.Header.Get("anthropic-version"); version != anthropicAPIVersion {
+			t.Errorf("anthropic-version = %q, want %s", version, anthropicAPIVersion)
+		}
+		if got != nil {
+			if err := json.NewDecoder(r.Body).Decode(got); err != nil {
+				t.Errorf("failed to decode request: %v", err)
+			}
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		w.Write([]byte(reply))
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func newTestAnthropicClient(t *testing.T, baseURL string) *AnthropicClient {
+	t.Helper()
+
+	client, err := NewAnthropicClient(&Config{
+		Provider:  "anthropic",
+		APIKey:    "test-key",
+		Model:     "claude-test",
+		MaxTokens: 123,
+		BaseURL:   baseURL,
+	})
+	if err != nil {
+		t.Fatalf("NewAnthropicClient returned error: %v", err)
+	}
+	return client
+}
+
+func TestAnthropicExplainError(t *testing.T) {
+	var got anthropicRequest
+	server := newAnthropicTestServer(t, http.StatusOK,
+		`{"content":[{"type":"text","text":"You forgot to import fmt."}]}`, &got)
+	client := newTestAnthropicClient(t, server.URL)
+
+	explanation, err := client.ExplainError(context.Background(), &domain.Error{
+		Message:  "undefined: fmt",
+		Type:     domain.ErrorTypeUndefinedSymbol,
+		Severity: domain.SeverityError,
+		Language: domain.LanguageGo,
+	})
+	if err != nil {
+		t.Fatalf("ExplainError returned error: %v", err)
+	}
+	if explanation != "You forgot to import fmt." {
+		t.Errorf("explanation = %q", explanation)
+	}
+
+	if got.Model != "claude-test" || got.MaxTokens != 123 {
+		t.Errorf("model/max_tokens = %s/%d, want claude-test/123", got.Model, got.MaxTokens)
+	}
+	if got.System != defaultExplainSystem(t) {
+		t.Errorf("system = %q, want the explain system prompt", got.System)
+	}
+	if len(got.Messages) != 1 || !strings.Contains(got.Messages[0].Content, "undefined: fmt") {
+		t.Errorf("messages = %+v, want one user message with the error", got.Messages)
+	}
+}
+
+func TestAnthropicTranslateQuery(t *testing.T) {
+	server := newAnthropicTestServer(t, http.StatusOK,
+		`{"content":[{"type":"text","text":"Command: df -h\nExplanation: Shows disk space."}]}`, nil)
+	client := newTestAnthropicClient(t, server.URL)
+
+	command, err := client.TranslateQuery(context.Background(), "check disk space", "ubuntu")
+	if err != nil {
+		t.Fatalf("TranslateQuery returned error: %v", err)
+	}
+	if command.Command != "df -h" || command.Explanation != "Shows disk space." {
+		t.Errorf("command = %+v", command)
+	}
+	if command.Platform != domain.PlatformLinux {
+		t.Errorf("platform = %s, want %s", command.Platform, domain.PlatformLinux)
+	}
+}
+
+func TestAnthropicAPIError(t *testing.T) {
+	server := newAnthropicTestServer(t, http.StatusBadRequest,
+		`{"type":"error","error":{"type":"invalid_request_error","message":"max_tokens too large"}}`, nil)
+	client := newTestAnthropicClient(t, server.URL)
+
+	_, err := client.ExplainError(context.Background(), &domain.Error{Message: "boom"})
+	if err == nil || !strings.Contains(err.Error(), "max_tokens too large") {
+		t.Errorf("ExplainError error = %v, want the API error message", err)
+	}
+}
+
+func TestNewAnthropicClientMissingKey(t *testing.T) {
+	_, err := NewAnthropicClient(&Config{Provider: "anthropic"})
+	if !errors.Is(err, ErrMissingAPIKey) {
+		t.Errorf("NewAnthropicClient without key = %v, want ErrMissingAPIKey", err)
+	}
+}
+
+func TestAnthropicTimeout(t *testing.T) {
+	server := newHangingServer(t)
+
+	client, _ := NewAnthropicClient(&Config{APIKey: "test-key", BaseURL: server.URL, Timeout: 20 * time.Millisecond})
+
+	_, err := client.ExplainError(context.Background(), &domain.Error{Message: "boom"})
+	var timeout *TimeoutError
+	if !errors.As(err, &timeout) || !errors.Is(err, ErrTimeout) {
+		t.Fatalf("ExplainError error = %v, want a TimeoutError", err)
+	}
+	if timeout.Provider != "anthropic" {
+		t.Errorf("timeout provider = %s, want anthropic", timeout.Provider)
+	}
+}
+
+func TestAnthropicCanceled(t *testing.T) {
+	server := newHangingServer(t)
+
+	client, _ := NewAnthropicClient(&Config{APIKey: "test-key", BaseURL: server.URL})
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(20*time.Millisecond, cancel)
+
+	_, err := client.ExplainError(ctx, &domain.Error{Message: "boom"})
+	if !errors.Is(err, ErrCanceled) {
+		t.Errorf("ExplainError error = %v, want ErrCanceled", err)
+	}
+}
+
+// newHangingServer never answers until the test ends
+func newHangingServer(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	done := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-done
+	}))
+	t.Cleanup(server.Close)
+	t.Cleanup(func() { close(done) })
+	return server
+}
+
+func TestAnthropicExplainErrorStream(t *testing.T) {
+	var got anthropicRequest
+	events := "event: message_start\ndata: {\"type\":\"message_start\",\"message\":{\"usage\":{\"input_tokens\":42,\"output_tokens\":1}}}\n\n" +
+		"event: content_block_delta\ndata: {\"type\":\"content_block_delta\",\"delta\":{\"type\":\"text_delta\",\"text\":\"Import \"}}\n\n" +
+		"event: content_block_delta\ndata: {\"type\":\"content_block_delta\",\"delta\":{\"type\":\"text_delta\",\"text\":\"fmt.\"}}\n\n" +
+		"event: message_delta\ndata: {\"type\":\"message_delta\",\"usage\":{\"output_tokens\":7}}\n\n" +
+		"event: message_stop\ndata: {\"type\":\"message_stop\"}\n\n"
+	server := newAnthropicTestServer(t, http.StatusOK, events, &got)
+	client := newTestAnthropicClient(t, server.URL)
+
+	meter := &UsageMeter{}
+	ctx := WithUsageMeter(context.Background(), meter)
+
+	var chunks []string
+	explanation, err := client.ExplainErrorStream(ctx, &domain.Error{Message: "undefined: fmt"}, func(chunk string) {
+		chunks = append(chunks, chunk)
+	})
+	if err != nil {
+		t.Fatalf("ExplainErrorStream returned error: %v", err)
+	}
+	if !got.Stream {
+		t.Error("request should ask for a stream")
+	}
+	if explanation != "Import fmt." || len(chunks) != 2 {
+		t.Errorf("explanation = %q from chunks %q", explanation, chunks)
+	}
+
+	want := Usage{Provider: "anthropic", Model: "claude-test", InputTokens: 42, OutputTokens: 7}
+	if records := meter.Records(); len(records) != 1 || records[0] != want {
+		t.Errorf("usage = %+v, want %+v", records, want)
+	}
+}
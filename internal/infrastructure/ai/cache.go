@@ -0,0 +1,182 @@
+package ai
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+
+	"github.com/arnislvdev/go-guru-ui/internal/domain"
+)
+
+// Cache stores answers by key. Values are JSON.
+type Cache interface {
+	Get(key string) ([]byte, bool)
+	Put(key string, value []byte) error
+}
+
+// CachedClient wraps any Client and reuses earlier answers to the same prompt.
+// The key covers the provider, the task's model and everything sent to it, so a
+// changed prompt template or model never gets a stale answer. The output mode is applied
+// after the AI answers, so professional and WTF runs share entries.
+type CachedClient struct {
+	client  Client
+	cache   Cache
+	config  *Config
+	prompts *Prompts
+
+	// OnHit, if set, is called when an answer comes from the cache
+	OnHit func(provider string)
+}
+
+// NewCachedClient wraps client so that its answers are kept in cache. cfg is
+// what client was made with; its models and prompts are part of every key.
+func NewCachedClient(client Client, cache Cache, cfg *Config) *CachedClient {
+	return &CachedClient{
+		client:  client,
+		cache:   cache,
+		config:  cfg,
+		prompts: cfg.prompts(),
+	}
+}
+
+// ExplainError explains a programming error, from the cache when it can
+func (c *CachedClient) ExplainError(ctx context.Context, err *domain.Error) (string, error) {
+	req, promptErr := c.prompts.explainRequest(err)
+	if promptErr != nil {
+		return c.client.ExplainError(ctx, err)
+	}
+
+	key := c.key("explain", req)
+	if explanation, ok := c.getExplanation(key); ok {
+		return explanation, nil
+	}
+
+	explanation, explainErr := c.client.ExplainError(ctx, err)
+	if explainErr != nil {
+		return "", explainErr
+	}
+	c.put(key, explanation)
+	return explanation, nil
+}
+
+// ExplainErrorStream streams an explanation. A cached one arrives as a single chunk.
+func (c *CachedClient) ExplainErrorStream(ctx context.Context, err *domain.Error, onChunk func(string)) (string, error) {
+	req, promptErr := c.prompts.explainRequest(err)
+	if promptErr != nil {
+		return StreamExplanation(ctx, c.client, err, onChunk)
+	}
+
+	key := c.key("explain", req)
+	if explanation, ok := c.getExplanation(key); ok {
+		onChunk(explanation)
+		return explanation, nil
+	}
+
+	explanation, explainErr := StreamExplanation(ctx, c.client, err, onChunk)
+	if explainErr != nil {
+		return "", explainErr
+	}
+	c.put(key, explanation)
+	return explanation, nil
+}
+
+// TranslateQuery converts natural language to CLI commands, from the cache when it can
+func (c *CachedClient) TranslateQuery(ctx context.Context, query, contextInfo string) (*domain.Command, error) {
+	req, promptErr := c.prompts.translateRequest(query, contextInfo)
+	if promptErr != nil {
+		return c.client.TranslateQuery(ctx, query, contextInfo)
+	}
+
+	key := c.key("translate", req)
+	if data, ok := c.cache.Get(key); ok {
+		var command domain.Command
+		if json.Unmarshal(data, &command) == nil && command.Command != "" {
+			c.hit()
+			return &command, nil
+		}
+	}
+
+	command, err := c.client.TranslateQuery(ctx, query, contextInfo)
+	if err != nil {
+		return nil, err
+	}
+	c.put(key, command)
+	return command, nil
+}
+
+// Chat answers a follow-up question, from the cache when the whole conversation matches
+func (c *CachedClient) Chat(ctx context.Context, err *domain.Error, messages []domain.Message) (string, error) {
+	req, promptErr := c.prompts.chatRequest(ctx, err, messages)
+	if promptErr != nil {
+		return c.client.Chat(ctx, err, messages)
+	}
+
+	key := c.key("chat", req)
+	if reply, ok := c.getExplanation(key); ok {
+		return reply, nil
+	}
+
+	reply, chatErr := c.client.Chat(ctx, err, messages)
+	if chatErr != nil {
+		return "", chatErr
+	}
+	c.put(key, reply)
+	return reply, nil
+}
+
+// GetProvider returns the wrapped client's provider name
+func (c *CachedClient) GetProvider() string {
+	return c.client.GetProvider()
+}
+
+// getExplanation reads a cached explanation
+func (c *CachedClient) getExplanation(key string) (string, bool) {
+	data, ok := c.cache.Get(key)
+	if !ok {
+		return "", false
+	}
+
+	var explanation string
+	if json.Unmarshal(data, &explanation) != nil || explanation == "" {
+		return "", false
+	}
+	c.hit()
+	return explanation, true
+}
+
+// put stores an answer. A cache that can't be written only costs a future
+// request, so the answer is still returned.
+func (c *CachedClient) put(key string, value any) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return
+	}
+	_ = c.cache.Put(key, data)
+}
+
+// hit reports a cache hit
+func (c *CachedClient) hit() {
+	if c.OnHit != nil {
+		c.OnHit(c.client.GetProvider())
+	}
+}
+
+// key hashes what decides the answer: provider, model, operation and the rendered prompts
+func (c *CachedClient) key(operation string, req completion) string {
+	model := c.config.ModelFor(req.Task)
+	if model == "" {
+		model = "default"
+	}
+
+	h := sha256.New()
+	parts := []string{c.client.GetProvider(), model, operation, req.System}
+	for _, message := range req.Messages {
+		parts = append(parts, message.Content)
+	}
+	for _, part := range parts {
+		h.Write([]byte(part))
+		h.Write([]byte{0})
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
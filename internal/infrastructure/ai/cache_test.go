@@ -0,0 +1,131 @@
+package ai
+
+import (
+	"context"
+	"testing"
+
+	"github.com/arnislvdev/go-guru-ui/internal/domain"
+)
+
+// memoryCache is a Cache held in a map
+type memoryCache map[string][]byte
+
+func (m memoryCache) Get(key string) ([]byte, bool) {
+	value, ok := m[key]
+	return value, ok
+}
+
+func (m memoryCache) Put(key string, value []byte) error {
+	m[key] = value
+	return nil
+}
+
+func TestCachedClientReusesAnswers(t *testing.T) {
+	flaky := &flakyClient{}
+	cached := NewCachedClient(flaky, memoryCache{}, &Config{Model: "gpt-4"})
+
+	hits := 0
+	cached.OnHit = func(provider string) { hits++ }
+
+	err := &domain.Error{Message: "undefined: fmt", Type: domain.ErrorTypeUndefinedSymbol}
+	for i := 0; i < 2; i++ {
+		explanation, explainErr := cached.ExplainError(context.Background(), err)
+		if explainErr != nil || explanation != "explained" {
+			t.Fatalf("ExplainError = %q, %v", explanation, explainErr)
+		}
+	}
+
+	var chunks []string
+	if _, streamErr := cached.ExplainErrorStream(context.Background(), err, func(chunk string) {
+		chunks = append(chunks, chunk)
+	}); streamErr != nil {
+		t.Fatalf("ExplainErrorStream returned error: %v", streamErr)
+	}
+
+	if flaky.calls != 1 || hits != 2 {
+		t.Errorf("provider called %d times with %d hits, want 1 call and 2 hits", flaky.calls, hits)
+	}
+	if len(chunks) != 1 || chunks[0] != "explained" {
+		t.Errorf("chunks = %q, want the cached explanation", chunks)
+	}
+}
+
+func TestCachedClientKeysOnConversation(t *testing.T) {
+	flaky := &flakyClient{}
+	cached := NewCachedClient(flaky, memoryCache{}, &Config{Model: "gpt-4"})
+
+	err := &domain.Error{Message: "undefined: fmt"}
+	why := []domain.Message{{Role: domain.RoleAssistant, Content: "Import fmt."}, {Role: domain.RoleUser, Content: "Why?"}}
+	example := []domain.Message{{Role: domain.RoleAssistant, Content: "Import fmt."}, {Role: domain.RoleUser, Content: "Show an example"}}
+	for _, messages := range [][]domain.Message{why, example, why} {
+		if _, chatErr := cached.Chat(context.Background(), err, messages); chatErr != nil {
+			t.Fatalf("Chat returned error: %v", chatErr)
+		}
+	}
+
+	if flaky.calls != 2 {
+		t.Errorf("provider called %d times, want 2 (the repeated question is cached)", flaky.calls)
+	}
+}
+
+func TestCachedClientKeysOnPromptAndModel(t *testing.T) {
+	store := memoryCache{}
+	flaky := &flakyClient{}
+
+	if _, err := NewCachedClient(flaky, store, &Config{Model: "gpt-4"}).TranslateQuery(context.Background(), "list files", ""); err != nil {
+		t.Fatalf("TranslateQuery returned error: %v", err)
+	}
+	if _, err := NewCachedClient(flaky, store, &Config{Model: "gpt-4"}).TranslateQuery(context.Background(), "list files", "macos"); err != nil {
+		t.Fatalf("TranslateQuery returned error: %v", err)
+	}
+	if _, err := NewCachedClient(flaky, store, &Config{Model: "gpt-4o"}).TranslateQuery(context.Background(), "list files", ""); err != nil {
+		t.Fatalf("TranslateQuery returned error: %v", err)
+	}
+	command, err := NewCachedClient(flaky, store, &Config{Model: "gpt-4"}).TranslateQuery(context.Background(), "list files", "")
+	if err != nil {
+		t.Fatalf("TranslateQuery returned error: %v", err)
+	}
+
+	if flaky.calls != 3 || command.Command != "ls" {
+		t.Errorf("provider called %d times, command %q; want 3 calls and the cached ls", flaky.calls, command.Command)
+	}
+}
+
+func TestCachedClientKeysOnTaskModel(t *testing.T) {
+	store := memoryCache{}
+	flaky := &flakyClient{}
+	err := &domain.Error{Message: "undefined: fmt"}
+
+	cheap := &Config{Model: "gpt-4o", Models: map[string]string{TaskTranslate: "gpt-4o-mini"}}
+	if _, explainErr := NewCachedClient(flaky, store, cheap).ExplainError(context.Background(), err); explainErr != nil {
+		t.Fatalf("ExplainError returned error: %v", explainErr)
+	}
+
+	// Same explain model, different translate model: the explanation is still cached
+	other := &Config{Model: "gpt-4o", Models: map[string]string{TaskTranslate: "gpt-3.5-turbo"}}
+	if _, explainErr := NewCachedClient(flaky, store, other).ExplainError(context.Background(), err); explainErr != nil {
+		t.Fatalf("ExplainError returned error: %v", explainErr)
+	}
+
+	// A different explain model asks again
+	strong := &Config{Model: "gpt-4o", Models: map[string]string{TaskExplain: "o3"}}
+	if _, explainErr := NewCachedClient(flaky, store, strong).ExplainError(context.Background(), err); explainErr != nil {
+		t.Fatalf("ExplainError returned error: %v", explainErr)
+	}
+
+	if flaky.calls != 2 {
+		t.Errorf("provider called %d times, want 2", flaky.calls)
+	}
+}
+
+func TestCachedClientSkipsFailures(t *testing.T) {
+	flaky := &flakyClient{errs: []error{ErrCanceled}}
+	cached := NewCachedClient(flaky, memoryCache{}, &Config{Model: "gpt-4"})
+
+	if _, err := cached.ExplainError(context.Background(), &domain.Error{}); err == nil {
+		t.Fatal("expected the provider's error")
+	}
+	if _, err := cached.ExplainError(context.Background(), &domain.Error{}); err != nil || flaky.calls != 2 {
+		t.Errorf("err = %v after %d calls, want a fresh answer after the failure", err, flaky.calls)
+	}
+}
@@ -0,0 +1,255 @@
+package ai
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sync"
+
+	"github.com/arnislvdev/go-guru-ui/internal/domain"
+)
+
+// Cassette modes
+const (
+	CassetteRecord = "record"
+	CassetteReplay = "replay"
+)
+
+// ErrNoRecording means a replayed cassette has no answer for the request
+var ErrNoRecording = errors.New("no recorded answer")
+
+// Cassette is a file of recorded requests and answers, used to replay AI
+// conversations without a provider, e.g. in tests
+type Cassette struct {
+	path string
+
+	mu           sync.Mutex
+	Interactions []Interaction `json:"interactions"`
+}
+
+// Interaction is one recorded request and its answer
+type Interaction struct {
+	Request  CassetteRequest  `json:"request"`
+	Response CassetteResponse `json:"response"`
+}
+
+// CassetteRequest is what was asked. Replays match on all of it.
+type CassetteRequest struct {
+	Operation string           `json:"operation"` // "explain", "translate" or "chat"
+	Error     *domain.Error    `json:"error,omitempty"`
+	Messages  []domain.Message `json:"messages,omitempty"`
+	Query     string           `json:"query,omitempty"`
+	Context   string           `json:"context,omitempty"`
+}
+
+// CassetteResponse is the answer that was given
+type CassetteResponse struct {
+	Provider    string          `json:"provider"`
+	Explanation string          `json:"explanation,omitempty"`
+	Command     *domain.Command `json:"command,omitempty"`
+}
+
+// LoadCassette reads the cassette at path. A missing file is an empty
+// cassette, ready to record into.
+func LoadCassette(path string) (*Cassette, error) {
+	cassette := &Cassette{path: path}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return cassette, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read cassette: %w", err)
+	}
+	if err := json.Unmarshal(data, cassette); err != nil {
+		return nil, fmt.Errorf("failed to parse cassette %s: %w", path, err)
+	}
+	return cassette, nil
+}
+
+// Len returns how many interactions the cassette holds
+func (c *Cassette) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.Interactions)
+}
+
+// find returns the first answer recorded for req
+func (c *Cassette) find(req CassetteRequest) (CassetteResponse, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, interaction := range c.Interactions {
+		if reflect.DeepEqual(interaction.Request, req) {
+			return interaction.Response, true
+		}
+	}
+	return CassetteResponse{}, false
+}
+
+// add records an interaction, replacing an older answer to the same request, and saves the file
+func (c *Cassette) add(interaction Interaction) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	replaced := false
+	for i := range c.Interactions {
+		if reflect.DeepEqual(c.Interactions[i].Request, interaction.Request) {
+			c.Interactions[i], replaced = interaction, true
+			break
+		}
+	}
+	if !replaced {
+		c.Interactions = append(c.Interactions, interaction)
+	}
+
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode cassette: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(c.path), 0o755); err != nil {
+		return fmt.Errorf("failed to create cassette directory: %w", err)
+	}
+	if err := os.WriteFile(c.path, append(data, '\n'), 0o644); err != nil {
+		return fmt.Errorf("failed to write cassette: %w", err)
+	}
+	return nil
+}
+
+// RecordingClient wraps any Client and saves each answer to a cassette.
+// Failed requests are not recorded. An answer that can't be saved is still
+// returned; the failure goes to OnRecordError.
+type RecordingClient struct {
+	client   Client
+	cassette *Cassette
+
+	// OnRecordError, if set, is called when an answer couldn't be saved
+	OnRecordError func(err error)
+}
+
+// NewRecordingClient wraps client so that its answers are saved to cassette
+func NewRecordingClient(client Client, cassette *Cassette) *RecordingClient {
+	return &RecordingClient{client: client, cassette: cassette}
+}
+
+// ExplainError explains a programming error and records the answer
+func (r *RecordingClient) ExplainError(ctx context.Context, err *domain.Error) (string, error) {
+	explanation, explainErr := r.client.ExplainError(ctx, err)
+	if explainErr != nil {
+		return "", explainErr
+	}
+	r.record(explainRecord(err), CassetteResponse{Explanation: explanation})
+	return explanation, nil
+}
+
+// ExplainErrorStream streams an explanation and records the whole of it
+func (r *RecordingClient) ExplainErrorStream(ctx context.Context, err *domain.Error, onChunk func(string)) (string, error) {
+	explanation, explainErr := StreamExplanation(ctx, r.client, err, onChunk)
+	if explainErr != nil {
+		return "", explainErr
+	}
+	r.record(explainRecord(err), CassetteResponse{Explanation: explanation})
+	return explanation, nil
+}
+
+// TranslateQuery converts natural language to CLI commands and records the answer
+func (r *RecordingClient) TranslateQuery(ctx context.Context, query, contextInfo string) (*domain.Command, error) {
+	command, err := r.client.TranslateQuery(ctx, query, contextInfo)
+	if err != nil {
+		return nil, err
+	}
+	r.record(translateRecord(query, contextInfo), CassetteResponse{Command: command})
+	return command, nil
+}
+
+// Chat answers a follow-up question and records the reply
+func (r *RecordingClient) Chat(ctx context.Context, err *domain.Error, messages []domain.Message) (string, error) {
+	reply, chatErr := r.client.Chat(ctx, err, messages)
+	if chatErr != nil {
+		return "", chatErr
+	}
+	r.record(chatRecord(err, messages), CassetteResponse{Explanation: reply})
+	return reply, nil
+}
+
+// GetProvider returns the wrapped client's provider name
+func (r *RecordingClient) GetProvider() string {
+	return r.client.GetProvider()
+}
+
+// record saves one answer, reporting a failure to OnRecordError
+func (r *RecordingClient) record(req CassetteRequest, resp CassetteResponse) {
+	resp.Provider = r.client.GetProvider()
+	if err := r.cassette.add(Interaction{Request: req, Response: resp}); err != nil && r.OnRecordError != nil {
+		r.OnRecordError(fmt.Errorf("failed to record answer: %w", err))
+	}
+}
+
+// ReplayClient answers from a cassette and never contacts a provider.
+// A request that wasn't recorded fails with ErrNoRecording.
+type ReplayClient struct {
+	cassette *Cassette
+	provider string
+}
+
+// NewReplayClient returns a client that serves the answers in cassette
+func NewReplayClient(cassette *Cassette) *ReplayClient {
+	return &ReplayClient{cassette: cassette, provider: "replay"}
+}
+
+// ExplainError returns the recorded explanation for err
+func (r *ReplayClient) ExplainError(ctx context.Context, err *domain.Error) (string, error) {
+	resp, ok := r.cassette.find(explainRecord(err))
+	if !ok {
+		return "", fmt.Errorf("%w in %s for error %q", ErrNoRecording, r.cassette.path, err.Message)
+	}
+	r.provider = resp.Provider
+	return resp.Explanation, nil
+}
+
+// TranslateQuery returns the recorded command for the query
+func (r *ReplayClient) TranslateQuery(ctx context.Context, query, contextInfo string) (*domain.Command, error) {
+	resp, ok := r.cassette.find(translateRecord(query, contextInfo))
+	if !ok || resp.Command == nil {
+		return nil, fmt.Errorf("%w in %s for query %q", ErrNoRecording, r.cassette.path, query)
+	}
+	r.provider = resp.Provider
+
+	// A copy, so callers can change it without changing the cassette
+	command := *resp.Command
+	return &command, nil
+}
+
+// Chat returns the recorded reply to the conversation
+func (r *ReplayClient) Chat(ctx context.Context, err *domain.Error, messages []domain.Message) (string, error) {
+	resp, ok := r.cassette.find(chatRecord(err, messages))
+	if !ok {
+		return "", fmt.Errorf("%w in %s for a follow-up about %q", ErrNoRecording, r.cassette.path, err.Message)
+	}
+	r.provider = resp.Provider
+	return resp.Explanation, nil
+}
+
+// GetProvider returns the provider of the last answer replayed, or "replay" before any
+func (r *ReplayClient) GetProvider() string {
+	return r.provider
+}
+
+// explainRecord is the cassette key for explaining err
+func explainRecord(err *domain.Error) CassetteRequest {
+	return CassetteRequest{Operation: "explain", Error: err}
+}
+
+// translateRecord is the cassette key for translating a query
+func translateRecord(query, contextInfo string) CassetteRequest {
+	return CassetteRequest{Operation: "translate", Query: query, Context: contextInfo}
+}
+
+// chatRecord is the cassette key for a follow-up conversation about err
+func chatRecord(err *domain.Error, messages []domain.Message) CassetteRequest {
+	return CassetteRequest{Operation: "chat", Error: err, Messages: messages}
+}
@@ -0,0 +1,103 @@
+package ai
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/arnislvdev/go-guru-ui/internal/domain"
+)
+
+func TestCassetteRecordAndReplay(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cassettes", "session.json")
+	parsed := &domain.Error{Message: "undefined: fmt", Type: domain.ErrorTypeUndefinedSymbol, Language: domain.LanguageGo}
+
+	cassette, err := LoadCassette(path)
+	if err != nil {
+		t.Fatalf("LoadCassette returned error: %v", err)
+	}
+	recorder := NewRecordingClient(&flakyClient{}, cassette)
+	if _, err := recorder.ExplainError(context.Background(), parsed); err != nil {
+		t.Fatalf("ExplainError returned error: %v", err)
+	}
+	if _, err := recorder.TranslateQuery(context.Background(), "list files", "linux"); err != nil {
+		t.Fatalf("TranslateQuery returned error: %v", err)
+	}
+	followUp := []domain.Message{{Role: domain.RoleAssistant, Content: "explained"}, {Role: domain.RoleUser, Content: "Why?"}}
+	if _, err := recorder.Chat(context.Background(), parsed, followUp); err != nil {
+		t.Fatalf("Chat returned error: %v", err)
+	}
+
+	// A fresh load sees what was saved
+	saved, err := LoadCassette(path)
+	if err != nil {
+		t.Fatalf("LoadCassette returned error: %v", err)
+	}
+	if saved.Len() != 3 {
+		t.Fatalf("cassette has %d interactions, want 3", saved.Len())
+	}
+
+	replay := NewReplayClient(saved)
+	explanation, err := replay.ExplainError(context.Background(), &domain.Error{Message: "undefined: fmt", Type: domain.ErrorTypeUndefinedSymbol, Language: domain.LanguageGo})
+	if err != nil || explanation != "explained" {
+		t.Errorf("replayed explanation = %q, %v", explanation, err)
+	}
+	if replay.GetProvider() != "flaky" {
+		t.Errorf("provider = %q, want the recorded flaky", replay.GetProvider())
+	}
+
+	command, err := replay.TranslateQuery(context.Background(), "list files", "linux")
+	if err != nil || command.Command != "ls" {
+		t.Errorf("replayed command = %+v, %v", command, err)
+	}
+
+	reply, err := replay.Chat(context.Background(), parsed, followUp)
+	if err != nil || reply != "replied" {
+		t.Errorf("replayed reply = %q, %v", reply, err)
+	}
+	if _, err := replay.Chat(context.Background(), parsed, followUp[:1]); !errors.Is(err, ErrNoRecording) {
+		t.Errorf("unrecorded conversation = %v, want ErrNoRecording", err)
+	}
+
+	if _, err := replay.TranslateQuery(context.Background(), "list files", "windows"); !errors.Is(err, ErrNoRecording) {
+		t.Errorf("unrecorded request = %v, want ErrNoRecording", err)
+	}
+}
+
+func TestRecordingClientSkipsFailures(t *testing.T) {
+	cassette, _ := LoadCassette(filepath.Join(t.TempDir(), "session.json"))
+	recorder := NewRecordingClient(&flakyClient{errs: []error{ErrCanceled}}, cassette)
+
+	if _, err := recorder.ExplainError(context.Background(), &domain.Error{}); !errors.Is(err, ErrCanceled) {
+		t.Errorf("err = %v, want the provider's error", err)
+	}
+	if cassette.Len() != 0 {
+		t.Errorf("a failed request should not be recorded")
+	}
+}
+
+func TestRecordingClientKeepsAnswerWhenSaveFails(t *testing.T) {
+	// A file where the cassette's folder should be stops it being written
+	dir := filepath.Join(t.TempDir(), "cassettes")
+	cassette, err := LoadCassette(filepath.Join(dir, "session.json"))
+	if err != nil {
+		t.Fatalf("LoadCassette returned error: %v", err)
+	}
+	if err := os.WriteFile(dir, nil, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	recorder := NewRecordingClient(&flakyClient{}, cassette)
+
+	var recordErr error
+	recorder.OnRecordError = func(err error) { recordErr = err }
+
+	explanation, err := recorder.ExplainErrorStream(context.Background(), &domain.Error{Message: "undefined: fmt"}, func(string) {})
+	if err != nil || explanation != "explained" {
+		t.Errorf("ExplainErrorStream = %q, %v; want the answer and no error", explanation, err)
+	}
+	if recordErr == nil {
+		t.Error("OnRecordError was not called")
+	}
+}
@@ -1,34 +1,177 @@
 package ai
 
 import (
+	"context"
+	"time"
+
 	"github.com/arnislvdev/go-guru-ui/internal/domain"
 )
 
 // Client defines the interface for AI providers
 type Client interface {
 	// ExplainError explains a programming error in plain English
-	ExplainError(err *domain.Error) (string, error)
+	ExplainError(ctx context.Context, err *domain.Error) (string, error)
 
 	// TranslateQuery converts natural language to CLI commands
-	TranslateQuery(query, contextInfo string) (*domain.Command, error)
+	TranslateQuery(ctx context.Context, query, contextInfo string) (*domain.Command, error)
+
+	// Chat continues a conversation about err. The messages follow the
+	// request to explain err: the explanation, then questions and answers,
+	// ending with the user's new question. It returns the reply.
+	Chat(ctx context.Context, err *domain.Error, messages []domain.Message) (string, error)
 
 	// GetProvider returns the name of the AI provider
 	GetProvider() string
 }
 
+// Streamer is implemented by clients that can deliver an explanation piece by piece
+type Streamer interface {
+	// ExplainErrorStream passes text to onChunk as it arrives and returns the full explanation
+	ExplainErrorStream(ctx context.Context, err *domain.Error, onChunk func(string)) (string, error)
+}
+
+// StreamExplanation streams an explanation when the client supports it.
+// Other clients answer in one go and onChunk receives the whole explanation at once.
+func StreamExplanation(ctx context.Context, client Client, err *domain.Error, onChunk func(string)) (string, error) {
+	if streamer, ok := client.(Streamer); ok {
+		return streamer.ExplainErrorStream(ctx, err, onChunk)
+	}
+
+	explanation, explainErr := client.ExplainError(ctx, err)
+	if explainErr != nil {
+		return "", explainErr
+	}
+	onChunk(explanation)
+	return explanation, nil
+}
+
+// Tasks a model can be chosen for
+const (
+	TaskExplain   = "explain"
+	TaskTranslate = "translate"
+	TaskChat      = "chat"
+)
+
+// Tasks returns every task, in the order settings list them
+func Tasks() []string {
+	return []string{TaskExplain, TaskTranslate, TaskChat}
+}
+
+type chatTaskKey struct{}
+
+// WithChatTask returns a context whose Chat requests use the model for task
+// instead of TaskChat, for conversations that are really an explanation
+func WithChatTask(ctx context.Context, task string) context.Context {
+	return context.WithValue(ctx, chatTaskKey{}, task)
+}
+
+// chatTask returns the task Chat requests in ctx are for
+func chatTask(ctx context.Context) string {
+	if task, ok := ctx.Value(chatTaskKey{}).(string); ok && task != "" {
+		return task
+	}
+	return TaskChat
+}
+
 // Config holds configuration for AI clients
 type Config struct {
 	Provider  string `json:"provider"`
 	APIKey    string `json:"api_key"`
-	Model     string `json:"model"`
+	Model     string `json:"model"` // empty means the provider's default model
 	MaxTokens int    `json:"max_tokens"`
+	BaseURL   string `json:"base_url,omitempty"` // empty means the provider's public API
+
+	// Models picks a model per task (TaskExplain, TaskTranslate, TaskChat); a task not listed uses Model
+	Models map[string]string `json:"models,omitempty"`
+
+	// Timeout caps each request to the provider; zero means no limit
+	Timeout time.Duration `json:"timeout,omitempty"`
+
+	// OpenAI-compatible gateways (vLLM, LM Studio, LiteLLM, Azure OpenAI)
+	Organization string            `json:"organization,omitempty"`
+	APIType      string            `json:"api_type,omitempty"`    // openai (default), azure or azure_ad
+	APIVersion   string            `json:"api_version,omitempty"` // required by Azure
+	Deployment   string            `json:"deployment,omitempty"`  // Azure deployment name; empty derives it from the model
+	Headers      map[string]string `json:"headers,omitempty"`     // sent with every request
+
+	// HTTP sets the proxy, TLS certificates and network timeouts
+	HTTP HTTPConfig `json:"http,omitempty"`
+
+	// Prompts are the templates requests are built from; nil means the built-in ones
+	Prompts *Prompts `json:"-"`
 }
 
 // DefaultConfig returns default AI configuration
 func DefaultConfig() *Config {
 	return &Config{
 		Provider:  "openai",
-		Model:     "gpt-4",
 		MaxTokens: 1000,
+		Timeout:   60 * time.Second,
+	}
+}
+
+// ModelFor returns the model set for task, or Model when the task has none.
+// Empty means the provider's default model.
+func (c *Config) ModelFor(task string) string {
+	return c.modelFor(task, c.Model)
+}
+
+// DefaultModel returns the model a provider uses when none is configured. It
+// is empty for providers that don't pick a model by name: llama.cpp serves
+// whatever it loaded, and the offline client has no model.
+func DefaultModel(provider string) string {
+	switch provider {
+	case "openai":
+		return defaultOpenAIModel
+	case "anthropic":
+		return defaultAnthropicModel
+	case LocalAPIOllama:
+		return defaultOllamaModel
+	}
+	return ""
+}
+
+// modelFor returns the model set for task, or fallback when the task has none
+func (c *Config) modelFor(task, fallback string) string {
+	if model := c.Models[task]; model != "" {
+		return model
+	}
+	return fallback
+}
+
+// modelOr returns the configured model, or fallback when none is set
+func (c *Config) modelOr(fallback string) string {
+	if c.Model != "" {
+		return c.Model
+	}
+	return fallback
+}
+
+// prompts returns the configured prompt templates, or the built-in ones
+func (c *Config) prompts() *Prompts {
+	if c.Prompts != nil {
+		return c.Prompts
+	}
+	return DefaultPrompts()
+}
+
+// withTimeout bounds ctx by the configured request timeout
+func (c *Config) withTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	if c.Timeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, c.Timeout)
+}
+
+// contextError turns an expired or canceled ctx into ErrCanceled or a *TimeoutError.
+// It returns nil while ctx is still live, so callers can fall through to their own error.
+func (c *Config) contextError(ctx context.Context, provider string) error {
+	switch ctx.Err() {
+	case nil:
+		return nil
+	case context.DeadlineExceeded:
+		return &TimeoutError{Provider: provider, Timeout: c.Timeout}
+	default:
+		return ErrCanceled
 	}
 }
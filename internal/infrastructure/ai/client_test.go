@@ -0,0 +1,56 @@
+package ai
+
+import (
+	"context"
+	"testing"
+
+	"github.com/arnislvdev/go-guru-ui/internal/domain"
+)
+
+// staticClient answers every request with the same text and cannot stream
+type staticClient struct {
+	answer string
+}
+
+func (c *staticClient) ExplainError(ctx context.Context, err *domain.Error) (string, error) {
+	return c.answer, nil
+}
+
+func (c *staticClient) TranslateQuery(ctx context.Context, query, contextInfo string) (*domain.Command, error) {
+	return &domain.Command{Command: c.answer}, nil
+}
+
+func (c *staticClient) Chat(ctx context.Context, err *domain.Error, messages []domain.Message) (string, error) {
+	return c.answer, nil
+}
+
+func (c *staticClient) GetProvider() string {
+	return "static"
+}
+
+func TestStreamExplanationFallsBack(t *testing.T) {
+	var chunks []string
+	explanation, err := StreamExplanation(context.Background(), &staticClient{answer: "all at once"}, &domain.Error{}, func(chunk string) {
+		chunks = append(chunks, chunk)
+	})
+	if err != nil {
+		t.Fatalf("StreamExplanation returned error: %v", err)
+	}
+	if explanation != "all at once" || len(chunks) != 1 || chunks[0] != "all at once" {
+		t.Errorf("explanation = %q, chunks = %q", explanation, chunks)
+	}
+}
+
+func TestConfigModelFor(t *testing.T) {
+	cfg := &Config{Model: "gpt-4o", Models: map[string]string{TaskTranslate: "gpt-4o-mini"}}
+
+	if got := cfg.ModelFor(TaskTranslate); got != "gpt-4o-mini" {
+		t.Errorf("ModelFor(translate) = %q, want gpt-4o-mini", got)
+	}
+	if got := cfg.ModelFor(TaskExplain); got != "gpt-4o" {
+		t.Errorf("ModelFor(explain) = %q, want the default gpt-4o", got)
+	}
+	if got := (&Config{}).ModelFor(TaskChat); got != "" {
+		t.Errorf("ModelFor(chat) with nothing set = %q, want empty", got)
+	}
+}
@@ -0,0 +1,166 @@
+package ai
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+)
+
+var (
+	// ErrMissingAPIKey is returned when a provider that needs an API key has none configured
+	ErrMissingAPIKey = errors.New("no API key configured")
+
+	// ErrTimeout matches any *TimeoutError
+	ErrTimeout = errors.New("AI request timed out")
+
+	// ErrCanceled is returned when the caller gave up on a request, e.g. with Ctrl-C
+	ErrCanceled = errors.New("AI request canceled")
+
+	// ErrInvalidResponse is returned when a model's answer can't be understood, even after a repair attempt
+	ErrInvalidResponse = errors.New("the AI's answer was not in the expected format")
+)
+
+// TimeoutError is returned when a provider does not answer within Config.Timeout
+type TimeoutError struct {
+	Provider string
+	Timeout  time.Duration
+}
+
+func (e *TimeoutError) Error() string {
+	if e.Timeout > 0 {
+		return fmt.Sprintf("%s did not answer within %s; try again or raise ai.timeout", e.Provider, e.Timeout)
+	}
+	return fmt.Sprintf("%s did not answer in time; try again or raise ai.timeout", e.Provider)
+}
+
+// Is lets errors.Is(err, ErrTimeout) match
+func (e *TimeoutError) Is(target error) bool {
+	return target == ErrTimeout
+}
+
+// APIError is a provider's API answering with an error status
+type APIError struct {
+	Provider   string
+	StatusCode int // zero when the error arrived inside a stream without a status
+	Message    string
+	RetryAfter time.Duration // how long the provider asked us to wait, if it said
+	Err        error         // the underlying client error, if any
+}
+
+func (e *APIError) Error() string {
+	if e.StatusCode > 0 {
+		return fmt.Sprintf("%s API error: status %d: %s", e.Provider, e.StatusCode, e.Message)
+	}
+	return fmt.Sprintf("%s API error: %s", e.Provider, e.Message)
+}
+
+func (e *APIError) Unwrap() error {
+	return e.Err
+}
+
+// Temporary reports whether trying the same request again later might work
+func (e *APIError) Temporary() bool {
+	switch e.StatusCode {
+	case http.StatusRequestTimeout, http.StatusTooManyRequests,
+		http.StatusInternalServerError, http.StatusBadGateway,
+		http.StatusServiceUnavailable, http.StatusGatewayTimeout,
+		529: // Anthropic: overloaded
+		return true
+	}
+	return false
+}
+
+// IsRetryable reports whether err is a passing failure (rate limit, server
+// trouble, dropped connection, slow answer) rather than one that will repeat
+func IsRetryable(err error) bool {
+	if err == nil || errors.Is(err, ErrCanceled) || errors.Is(err, ErrMissingAPIKey) {
+		return false
+	}
+	if errors.Is(err, ErrTimeout) {
+		return true
+	}
+
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.Temporary()
+	}
+
+	if isSetupError(err) {
+		return false
+	}
+
+	// Every *url.Error is a net.Error, even a bad scheme; only its timeouts pass
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return true
+	}
+	return errors.Is(err, syscall.ECONNRESET) || errors.Is(err, syscall.ECONNREFUSED) ||
+		errors.Is(err, io.ErrUnexpectedEOF)
+}
+
+// isSetupError reports whether err comes from how the connection is set up:
+// a certificate that isn't trusted or doesn't match, or a URL with a scheme
+// Go can't speak. These repeat on every try and with no change to the
+// settings, so they are shown rather than retried or hidden by a fallback.
+func isSetupError(err error) bool {
+	var certErr *tls.CertificateVerificationError
+	var unknownAuthority x509.UnknownAuthorityError
+	var hostnameErr x509.HostnameError
+	var invalidErr x509.CertificateInvalidError
+	if errors.As(err, &certErr) || errors.As(err, &unknownAuthority) ||
+		errors.As(err, &hostnameErr) || errors.As(err, &invalidErr) {
+		return true
+	}
+
+	var urlErr *url.Error
+	return errors.As(err, &urlErr) && strings.Contains(urlErr.Err.Error(), "unsupported protocol scheme")
+}
+
+// retryAfter returns how long the provider asked us to wait, or zero
+func retryAfter(err error) time.Duration {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.RetryAfter
+	}
+	return 0
+}
+
+// parseRetryAfter reads a Retry-After header, which is either seconds or an HTTP date
+func parseRetryAfter(value string) time.Duration {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	if when, err := http.ParseTime(value); err == nil {
+		if wait := time.Until(when); wait > 0 {
+			return wait
+		}
+	}
+	return 0
+}
+
+// IsInvalidRequest reports whether the provider rejected the request itself
+// (bad prompt, too long, malformed), which another provider would likely reject too
+func IsInvalidRequest(err error) bool {
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		return false
+	}
+	switch apiErr.StatusCode {
+	case http.StatusBadRequest, http.StatusRequestEntityTooLarge, http.StatusUnprocessableEntity:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,121 @@
+package ai
+
+import (
+	"context"
+	"errors"
+
+	"github.com/arnislvdev/go-guru-ui/internal/domain"
+)
+
+// FallbackClient tries a list of clients in order until one answers.
+// Errors that another provider would hit too (a rejected prompt, Ctrl-C) stop
+// the chain instead of being passed down it.
+type FallbackClient struct {
+	clients  []Client
+	answered Client
+
+	// OnFallback, if set, is called when one provider failed and the next is tried
+	OnFallback func(failed, next string, err error)
+}
+
+// NewFallbackClient returns a client that tries clients in the given order
+func NewFallbackClient(clients ...Client) *FallbackClient {
+	return &FallbackClient{clients: clients}
+}
+
+// ExplainError explains a programming error with the first provider that answers
+func (f *FallbackClient) ExplainError(ctx context.Context, err *domain.Error) (string, error) {
+	var explanation string
+	fallbackErr := f.do(func(client Client) error {
+		var callErr error
+		explanation, callErr = client.ExplainError(ctx, err)
+		return callErr
+	})
+	return explanation, fallbackErr
+}
+
+// ExplainErrorStream streams an explanation from the first provider that answers.
+// Once text has reached onChunk the chain stops, so nothing is printed twice.
+func (f *FallbackClient) ExplainErrorStream(ctx context.Context, err *domain.Error, onChunk func(string)) (string, error) {
+	var explanation string
+	started := false
+
+	fallbackErr := f.do(func(client Client) error {
+		var callErr error
+		explanation, callErr = StreamExplanation(ctx, client, err, func(chunk string) {
+			started = true
+			onChunk(chunk)
+		})
+		if callErr != nil && started {
+			return permanent{callErr}
+		}
+		return callErr
+	})
+	return explanation, fallbackErr
+}
+
+// TranslateQuery converts natural language to CLI commands with the first provider that answers
+func (f *FallbackClient) TranslateQuery(ctx context.Context, query, contextInfo string) (*domain.Command, error) {
+	var command *domain.Command
+	fallbackErr := f.do(func(client Client) error {
+		var callErr error
+		command, callErr = client.TranslateQuery(ctx, query, contextInfo)
+		return callErr
+	})
+	return command, fallbackErr
+}
+
+// Chat answers a follow-up question with the first provider that answers
+func (f *FallbackClient) Chat(ctx context.Context, err *domain.Error, messages []domain.Message) (string, error) {
+	var reply string
+	fallbackErr := f.do(func(client Client) error {
+		var callErr error
+		reply, callErr = client.Chat(ctx, err, messages)
+		return callErr
+	})
+	return reply, fallbackErr
+}
+
+// GetProvider returns the provider that answered last, or the first one before any call
+func (f *FallbackClient) GetProvider() string {
+	if f.answered != nil {
+		return f.answered.GetProvider()
+	}
+	if len(f.clients) > 0 {
+		return f.clients[0].GetProvider()
+	}
+	return "none"
+}
+
+// do runs call against each client in turn until one succeeds or the error
+// should not be passed on. It returns the last error when every client failed.
+func (f *FallbackClient) do(call func(Client) error) error {
+	if len(f.clients) == 0 {
+		return errors.New("no AI providers configured")
+	}
+
+	var err error
+	for i, client := range f.clients {
+		err = call(client)
+		if err == nil {
+			f.answered = client
+			return nil
+		}
+		if p, ok := err.(permanent); ok {
+			return p.err
+		}
+		if !shouldFallBack(err) {
+			return err
+		}
+
+		if i+1 < len(f.clients) && f.OnFallback != nil {
+			f.OnFallback(client.GetProvider(), f.clients[i+1].GetProvider(), err)
+		}
+	}
+	return err
+}
+
+// shouldFallBack reports whether another provider might succeed where this one failed
+func shouldFallBack(err error) bool {
+	return !errors.Is(err, ErrCanceled) && !IsInvalidRequest(err) && !isSetupError(err)
+}
@@ -0,0 +1,87 @@
+package ai
+
+import (
+	"context"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/arnislvdev/go-guru-ui/internal/domain"
+)
+
+// namedClient is a flakyClient with its own provider name
+type namedClient struct {
+	flakyClient
+	name string
+}
+
+func (c *namedClient) GetProvider() string {
+	return c.name
+}
+
+func TestFallbackClientTriesNextProvider(t *testing.T) {
+	primary := &namedClient{name: "openai", flakyClient: flakyClient{errs: []error{
+		&APIError{Provider: "OpenAI", StatusCode: http.StatusTooManyRequests},
+	}}}
+	backup := &namedClient{name: "anthropic"}
+	fallback := NewFallbackClient(primary, backup)
+
+	var hops []string
+	fallback.OnFallback = func(failed, next string, err error) {
+		hops = append(hops, failed+"->"+next)
+	}
+
+	explanation, err := fallback.ExplainError(context.Background(), &domain.Error{})
+	if err != nil {
+		t.Fatalf("ExplainError returned error: %v", err)
+	}
+	if explanation != "explained" || fallback.GetProvider() != "anthropic" {
+		t.Errorf("explanation = %q from %s, want an answer from anthropic", explanation, fallback.GetProvider())
+	}
+	if len(hops) != 1 || hops[0] != "openai->anthropic" {
+		t.Errorf("hops = %v", hops)
+	}
+}
+
+func TestFallbackClientStopsOnInvalidRequest(t *testing.T) {
+	invalid := &APIError{Provider: "OpenAI", StatusCode: http.StatusBadRequest}
+	primary := &namedClient{name: "openai", flakyClient: flakyClient{errs: []error{invalid}}}
+	backup := &namedClient{name: "anthropic"}
+	fallback := NewFallbackClient(primary, backup)
+
+	_, err := fallback.TranslateQuery(context.Background(), "list files", "")
+	if !errors.Is(err, invalid) || backup.calls != 0 {
+		t.Errorf("err = %v, backup calls = %d; an invalid prompt should not cascade", err, backup.calls)
+	}
+}
+
+func TestFallbackClientStopsOnSetupError(t *testing.T) {
+	untrusted := fmt.Errorf("openai request failed: %w", &url.Error{Op: "Post", URL: "https://llm.corp", Err: x509.UnknownAuthorityError{}})
+	primary := &namedClient{name: "openai", flakyClient: flakyClient{errs: []error{untrusted}}}
+	backup := &namedClient{name: "offline"}
+	fallback := NewFallbackClient(primary, backup)
+
+	_, err := fallback.ExplainError(context.Background(), &domain.Error{})
+	if !errors.Is(err, untrusted) || backup.calls != 0 {
+		t.Errorf("err = %v, backup calls = %d; a certificate problem should be shown, not hidden", err, backup.calls)
+	}
+}
+
+func TestFallbackClientReturnsLastError(t *testing.T) {
+	down := &APIError{Provider: "x", StatusCode: http.StatusServiceUnavailable}
+	fallback := NewFallbackClient(
+		&namedClient{name: "openai", flakyClient: flakyClient{errs: []error{down}}},
+		&namedClient{name: "ollama", flakyClient: flakyClient{errs: []error{ErrMissingAPIKey}}},
+	)
+
+	_, err := fallback.ExplainError(context.Background(), &domain.Error{})
+	if !errors.Is(err, ErrMissingAPIKey) {
+		t.Errorf("err = %v, want the last provider's error", err)
+	}
+	if fallback.GetProvider() != "openai" {
+		t.Errorf("GetProvider before any answer = %s, want the first provider", fallback.GetProvider())
+	}
+}
@@ -0,0 +1,318 @@
+package ai
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/arnislvdev/go-guru-ui/internal/domain"
+)
+
+// Local server flavours
+const (
+	LocalAPIOllama   = "ollama"
+	LocalAPILlamaCpp = "llamacpp"
+)
+
+const (
+	defaultOllamaURL   = "http://localhost:11434"
+	defaultLlamaCppURL = "http://localhost:8080"
+	defaultOllamaModel = "llama3"
+)
+
+// LocalClient implements the AI Client interface using a model served on this machine
+// by Ollama (/api/chat) or the llama.cpp server (/completion). Nothing leaves the host.
+type LocalClient struct {
+	httpClient *http.Client
+	config     *Config
+	api        string
+	baseURL    string
+	model      string
+}
+
+// ollamaRequest is the body sent to Ollama's /api/chat
+type ollamaRequest struct {
+	Model    string         `json:"model"`
+	Messages []chatMessage  `json:"messages"`
+	Stream   bool           `json:"stream"`
+	Format   string         `json:"format,omitempty"` // "json" forces a JSON reply
+	Options  map[string]any `json:"options,omitempty"`
+}
+
+// ollamaChunk is one JSON line of an Ollama chat stream
+type ollamaChunk struct {
+	Message struct {
+		Content string `json:"content"`
+	} `json:"message"`
+	Done  bool   `json:"done"`
+	Error string `json:"error,omitempty"`
+
+	// Token counts, sent with the last chunk
+	PromptEvalCount int `json:"prompt_eval_count,omitempty"`
+	EvalCount       int `json:"eval_count,omitempty"`
+}
+
+// llamaCppRequest is the body sent to llama.cpp's /completion
+type llamaCppRequest struct {
+	Prompt   string `json:"prompt"`
+	NPredict int    `json:"n_predict,omitempty"`
+	Stream   bool   `json:"stream"`
+}
+
+// llamaCppChunk is one event of a llama.cpp completion stream
+type llamaCppChunk struct {
+	Content string `json:"content"`
+	Stop    bool   `json:"stop"`
+
+	// Token counts, sent with the last event
+	TokensEvaluated int `json:"tokens_evaluated,omitempty"`
+	TokensPredicted int `json:"tokens_predicted,omitempty"`
+}
+
+func init() {
+	Register(LocalAPIOllama, func(cfg *Config) (Client, error) {
+		return NewLocalClient(cfg, LocalAPIOllama)
+	})
+	Register(LocalAPILlamaCpp, func(cfg *Config) (Client, error) {
+		return NewLocalClient(cfg, LocalAPILlamaCpp)
+	})
+}
+
+// NewLocalClient creates a client for a local Ollama or llama.cpp server.
+// No API key is needed; BaseURL and Model default to the server's usual values.
+func NewLocalClient(cfg *Config, api string) (*LocalClient, error) {
+	if cfg == nil {
+		cfg = DefaultConfig()
+	}
+
+	baseURL := cfg.BaseURL
+	switch api {
+	case LocalAPIOllama:
+		if baseURL == "" {
+			baseURL = defaultOllamaURL
+		}
+	case LocalAPILlamaCpp:
+		if baseURL == "" {
+			baseURL = defaultLlamaCppURL
+		}
+	default:
+		return nil, fmt.Errorf("unknown local server API %q (use %s or %s)", api, LocalAPIOllama, LocalAPILlamaCpp)
+	}
+
+	httpClient, err := newHTTPClient(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	return &LocalClient{
+		httpClient: httpClient,
+		config:     cfg,
+		api:        api,
+		baseURL:    strings.TrimRight(baseURL, "/"),
+		model:      cfg.modelOr(defaultOllamaModel),
+	}, nil
+}
+
+// ExplainError explains a programming error using the local model
+func (c *LocalClient) ExplainError(ctx context.Context, err *domain.Error) (string, error) {
+	req, promptErr := c.config.prompts().explainRequest(err)
+	if promptErr != nil {
+		return "", promptErr
+	}
+	return c.complete(ctx, req)
+}
+
+// ExplainErrorStream explains a programming error, passing text to onChunk as it arrives
+func (c *LocalClient) ExplainErrorStream(ctx context.Context, err *domain.Error, onChunk func(string)) (string, error) {
+	req, promptErr := c.config.prompts().explainRequest(err)
+	if promptErr != nil {
+		return "", promptErr
+	}
+	req.OnChunk = onChunk
+	return c.complete(ctx, req)
+}
+
+// Chat answers a follow-up question about err using the local model
+func (c *LocalClient) Chat(ctx context.Context, err *domain.Error, messages []domain.Message) (string, error) {
+	req, promptErr := c.config.prompts().chatRequest(ctx, err, messages)
+	if promptErr != nil {
+		return "", promptErr
+	}
+	return c.complete(ctx, req)
+}
+
+// TranslateQuery converts natural language to CLI commands
+func (c *LocalClient) TranslateQuery(ctx context.Context, query, contextInfo string) (*domain.Command, error) {
+	return translateQuery(ctx, c, c.config.prompts(), query, contextInfo)
+}
+
+// GetProvider returns the provider name
+func (c *LocalClient) GetProvider() string {
+	return c.api
+}
+
+// complete sends the conversation to the local server and joins the streamed reply.
+// If req.OnChunk is set it also receives each piece of text as it arrives.
+func (c *LocalClient) complete(ctx context.Context, req completion) (string, error) {
+	ctx, cancel := c.config.withTimeout(ctx)
+	defer cancel()
+
+	model := c.config.modelFor(req.Task, c.model)
+
+	var path string
+	var body any
+
+	switch c.api {
+	case LocalAPILlamaCpp:
+		path = "/completion"
+		body = llamaCppRequest{
+			Prompt:   flattenConversation(req),
+			NPredict: c.config.MaxTokens,
+			Stream:   true,
+		}
+	default:
+		path = "/api/chat"
+		ollama := ollamaRequest{
+			Model:    model,
+			Messages: append([]chatMessage{{Role: "system", Content: req.System}}, req.Messages...),
+			Stream:   true,
+			Options:  map[string]any{"num_predict": c.config.MaxTokens},
+		}
+		if req.JSON {
+			ollama.Format = "json"
+		}
+		body = ollama
+	}
+
+	data, err := json.Marshal(body)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode %s request: %w", c.api, err)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL+path, bytes.NewReader(data))
+	if err != nil {
+		return "", fmt.Errorf("failed to create %s request: %w", c.api, err)
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.httpClient.Do(httpReq)
+	if err != nil {
+		if ctxErr := c.config.contextError(ctx, c.GetProvider()); ctxErr != nil {
+			return "", ctxErr
+		}
+		return "", fmt.Errorf("%s server error (is it running at %s?): %w", c.api, c.baseURL, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return "", &APIError{
+			Provider:   c.api,
+			StatusCode: resp.StatusCode,
+			Message:    strings.TrimSpace(string(msg)),
+			RetryAfter: parseRetryAfter(resp.Header.Get("Retry-After")),
+		}
+	}
+
+	var text strings.Builder
+	usage, err := c.readStream(resp.Body, func(chunk string) {
+		text.WriteString(chunk)
+		if req.OnChunk != nil && chunk != "" {
+			req.OnChunk(chunk)
+		}
+	})
+	if err != nil {
+		if ctxErr := c.config.contextError(ctx, c.GetProvider()); ctxErr != nil {
+			return "", ctxErr
+		}
+		return "", err
+	}
+
+	usage.Model = model
+	if usage.TotalTokens() == 0 {
+		usage = estimateUsage(c.GetProvider(), model, req, text.String())
+	}
+	reportUsage(ctx, usage)
+
+	if text.Len() == 0 {
+		return "", fmt.Errorf("no response from %s", c.api)
+	}
+
+	return text.String(), nil
+}
+
+// flattenConversation turns a conversation into one prompt for llama.cpp's
+// /completion, which takes raw text rather than chat messages
+func flattenConversation(req completion) string {
+	var prompt strings.Builder
+	prompt.WriteString(req.System)
+	prompt.WriteString("\n\n")
+
+	for _, message := range req.Messages {
+		if message.Role == "assistant" {
+			prompt.WriteString("Assistant: ")
+		} else {
+			prompt.WriteString("User: ")
+		}
+		prompt.WriteString(message.Content)
+		prompt.WriteString("\n\n")
+	}
+
+	prompt.WriteString("Assistant: ")
+	return prompt.String()
+}
+
+// readStream decodes a JSON-lines stream, passing each piece of text to onChunk,
+// and returns the token counts from the end of the stream.
+// Lines may carry an SSE "data: " prefix, which llama.cpp uses.
+func (c *LocalClient) readStream(r io.Reader, onChunk func(string)) (Usage, error) {
+	usage := Usage{Provider: c.GetProvider()}
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		line = strings.TrimPrefix(line, "data:")
+		line = strings.TrimSpace(line)
+		if line == "" || line == "[DONE]" {
+			continue
+		}
+
+		if c.api == LocalAPILlamaCpp {
+			var chunk llamaCppChunk
+			if err := json.Unmarshal([]byte(line), &chunk); err != nil {
+				return usage, fmt.Errorf("%s server sent bad stream data: %w", c.api, err)
+			}
+			onChunk(chunk.Content)
+			if chunk.Stop {
+				usage.InputTokens, usage.OutputTokens = chunk.TokensEvaluated, chunk.TokensPredicted
+				return usage, nil
+			}
+			continue
+		}
+
+		var chunk ollamaChunk
+		if err := json.Unmarshal([]byte(line), &chunk); err != nil {
+			return usage, fmt.Errorf("%s server sent bad stream data: %w", c.api, err)
+		}
+		if chunk.Error != "" {
+			return usage, &APIError{Provider: c.api, Message: chunk.Error}
+		}
+		onChunk(chunk.Message.Content)
+		if chunk.Done {
+			usage.InputTokens, usage.OutputTokens = chunk.PromptEvalCount, chunk.EvalCount
+			return usage, nil
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return usage, fmt.Errorf("failed to read %s stream: %w", c.api, err)
+	}
+	return usage, nil
+}
@@ -0,0 +1,125 @@
+package ai
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/arnislvdev/go-guru-ui/internal/domain"
+)
+
+func TestLocalOllamaExplainError(t *testing.T) {
+	var got ollamaRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/chat" {
+			t.Errorf("path = %s, want /api/chat", r.URL.Path)
+		}
+		json.NewDecoder(r.Body).Decode(&got)
+
+		w.Header().Set("Content-Type", "application/x-ndjson")
+		w.Write([]byte(`{"message":{"role":"assistant","content":"You forgot "},"done":false}` + "\n"))
+		w.Write([]byte(`{"message":{"role":"assistant","content":"to import fmt."},"done":false}` + "\n"))
+		w.Write([]byte(`{"message":{"role":"assistant","content":""},"done":true,"prompt_eval_count":30,"eval_count":9}` + "\n"))
+	}))
+	defer server.Close()
+
+	client, err := NewLocalClient(&Config{Model: "codellama", MaxTokens: 50, BaseURL: server.URL}, LocalAPIOllama)
+	if err != nil {
+		t.Fatalf("NewLocalClient returned error: %v", err)
+	}
+
+	meter := &UsageMeter{}
+	explanation, err := client.ExplainError(WithUsageMeter(context.Background(), meter), &domain.Error{Message: "undefined: fmt"})
+	if err != nil {
+		t.Fatalf("ExplainError returned error: %v", err)
+	}
+	if explanation != "You forgot to import fmt." {
+		t.Errorf("explanation = %q", explanation)
+	}
+	if got.Model != "codellama" || !got.Stream {
+		t.Errorf("request model/stream = %s/%v", got.Model, got.Stream)
+	}
+	if len(got.Messages) != 2 || got.Messages[0].Content != defaultExplainSystem(t) {
+		t.Errorf("messages = %+v, want system then user", got.Messages)
+	}
+	if records := meter.Records(); len(records) != 1 || records[0].InputTokens != 30 || records[0].OutputTokens != 9 {
+		t.Errorf("usage = %+v, want 30 in and 9 out", records)
+	}
+}
+
+func TestLocalOllamaChat(t *testing.T) {
+	var got ollamaRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&got)
+		w.Header().Set("Content-Type", "application/x-ndjson")
+		w.Write([]byte(`{"message":{"role":"assistant","content":"Because fmt is a package."},"done":true}` + "\n"))
+	}))
+	defer server.Close()
+
+	client, err := NewLocalClient(&Config{BaseURL: server.URL, Model: "codellama", Models: map[string]string{TaskChat: "qwen"}}, LocalAPIOllama)
+	if err != nil {
+		t.Fatalf("NewLocalClient returned error: %v", err)
+	}
+
+	reply, err := client.Chat(context.Background(), &domain.Error{Message: "undefined: fmt"}, []domain.Message{
+		{Role: domain.RoleAssistant, Content: "Import fmt."},
+		{Role: domain.RoleUser, Content: "Why?"},
+	})
+	if err != nil {
+		t.Fatalf("Chat returned error: %v", err)
+	}
+	if reply != "Because fmt is a package." {
+		t.Errorf("reply = %q", reply)
+	}
+	if got.Model != "qwen" {
+		t.Errorf("model = %q, want the chat model qwen", got.Model)
+	}
+
+	// system, the explain request, then the conversation
+	if len(got.Messages) != 4 || got.Messages[2].Content != "Import fmt." || got.Messages[3].Role != "user" || got.Messages[3].Content != "Why?" {
+		t.Errorf("messages = %+v, want system, explain request, answer, question", got.Messages)
+	}
+}
+
+func TestLocalLlamaCppTranslateQuery(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/completion" {
+			t.Errorf("path = %s, want /completion", r.URL.Path)
+		}
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Write([]byte("data: {\"content\":\"Command: df -h\\n\",\"stop\":false}\n\n"))
+		w.Write([]byte("data: {\"content\":\"Explanation: Shows disk space.\",\"stop\":false}\n\n"))
+		w.Write([]byte("data: {\"content\":\"\",\"stop\":true}\n\n"))
+	}))
+	defer server.Close()
+
+	client, err := NewLocalClient(&Config{BaseURL: server.URL}, LocalAPILlamaCpp)
+	if err != nil {
+		t.Fatalf("NewLocalClient returned error: %v", err)
+	}
+
+	command, err := client.TranslateQuery(context.Background(), "check disk space", "")
+	if err != nil {
+		t.Fatalf("TranslateQuery returned error: %v", err)
+	}
+	if command.Command != "df -h" || command.Explanation != "Shows disk space." {
+		t.Errorf("command = %+v", command)
+	}
+}
+
+func TestLocalStreamError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"error":"model 'nope' not found"}` + "\n"))
+	}))
+	defer server.Close()
+
+	client, _ := NewLocalClient(&Config{Model: "nope", BaseURL: server.URL}, LocalAPIOllama)
+
+	_, err := client.ExplainError(context.Background(), &domain.Error{Message: "boom"})
+	if err == nil || !strings.Contains(err.Error(), "not found") {
+		t.Errorf("ExplainError error = %v, want the server's error", err)
+	}
+}
@@ -0,0 +1,337 @@
+package ai
+
+import (
+	"context"
+	"embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"text/template"
+
+	"github.com/arnislvdev/go-guru-ui/internal/domain"
+)
+
+// ProviderOffline is the provider that answers from the built-in knowledge base
+const ProviderOffline = "offline"
+
+// ErrNoOfflineAnswer means the knowledge base has nothing for the question
+var ErrNoOfflineAnswer = errors.New("no offline answer")
+
+// ErrChatUnsupported means the client can explain an error but not hold a conversation about it
+var ErrChatUnsupported = errors.New("follow-up questions need an AI provider")
+
+//go:embed knowledge/*.json
+var knowledgeFiles embed.FS
+
+// OfflineClient implements the AI Client interface from an embedded knowledge
+// base of Go errors and common commands. It needs no key and no network.
+type OfflineClient struct {
+	kb *knowledgeBase
+}
+
+// knowledgeBase is the parsed form of knowledge/go.json
+type knowledgeBase struct {
+	errors   map[string][]*errorRule // by domain.ErrorType*
+	types    []string                // keys of errors, sorted, for a stable search order
+	commands []commandRule
+}
+
+// errorRule explains the errors its pattern matches. Without a pattern it is
+// the catch-all for its error type. Named groups in the pattern are available
+// to the templates, along with .message.
+type errorRule struct {
+	pattern     *regexp.Regexp
+	explanation *template.Template
+	cause       *template.Template
+	fix         *template.Template
+}
+
+// commandRule answers a question containing all of its keywords
+type commandRule struct {
+	Keywords    []string `json:"keywords"`
+	Linux       string   `json:"linux"`
+	MacOS       string   `json:"macos"`
+	Windows     string   `json:"windows"`
+	Explanation string   `json:"explanation"`
+}
+
+// knowledgeFile is how knowledge/go.json is laid out
+type knowledgeFile struct {
+	Errors map[string][]struct {
+		Pattern     string `json:"pattern"`
+		Explanation string `json:"explanation"`
+		Cause       string `json:"cause"`
+		Fix         string `json:"fix"`
+	} `json:"errors"`
+	Commands []commandRule `json:"commands"`
+}
+
+// knownErrorTypes are the keys a knowledge file may use
+var knownErrorTypes = map[string]bool{
+	domain.ErrorTypeUndefinedSymbol: true,
+	domain.ErrorTypeTypeMismatch:    true,
+	domain.ErrorTypeUnusedImport:    true,
+	domain.ErrorTypeUnusedVariable:  true,
+	domain.ErrorTypeMissingReturn:   true,
+	domain.ErrorTypeArgumentCount:   true,
+	domain.ErrorTypeUnknown:         true,
+}
+
+var (
+	offlineOnce sync.Once
+	offlineKB   *knowledgeBase
+	offlineErr  error
+)
+
+func init() {
+	Register(ProviderOffline, func(cfg *Config) (Client, error) {
+		return NewOfflineClient()
+	})
+}
+
+// NewOfflineClient creates a client that answers from the built-in knowledge base
+func NewOfflineClient() (*OfflineClient, error) {
+	offlineOnce.Do(func() {
+		offlineKB, offlineErr = loadKnowledgeBase("knowledge/go.json")
+	})
+	if offlineErr != nil {
+		return nil, offlineErr
+	}
+	return &OfflineClient{kb: offlineKB}, nil
+}
+
+// ExplainError explains a programming error from the knowledge base
+func (c *OfflineClient) ExplainError(ctx context.Context, err *domain.Error) (string, error) {
+	// The knowledge base only knows Go's messages
+	if err.Language != "" && err.Language != domain.LanguageGo {
+		return "", fmt.Errorf("%w for %s errors", ErrNoOfflineAnswer, err.Language)
+	}
+
+	rule, data := c.kb.match(err)
+	if rule == nil {
+		return "", fmt.Errorf("%w for %q", ErrNoOfflineAnswer, err.Message)
+	}
+
+	var sections []string
+	for _, part := range []struct {
+		heading string
+		tmpl    *template.Template
+	}{
+		{"What it means", rule.explanation},
+		{"Why it happens", rule.cause},
+		{"How to fix it", rule.fix},
+	} {
+		var text strings.Builder
+		if execErr := part.tmpl.Execute(&text, data); execErr != nil {
+			return "", fmt.Errorf("offline explanation failed: %w", execErr)
+		}
+		sections = append(sections, part.heading+": "+text.String())
+	}
+
+	if err.File != "" && err.Line > 0 {
+		sections = append(sections, fmt.Sprintf("Look at: %s line %d%s", err.File, err.Line, stackFunction(err)))
+	}
+	return strings.Join(sections, "\n\n"), nil
+}
+
+// stackFunction names the call at the error's location when it has a stack, as " (in main.run)"
+func stackFunction(err *domain.Error) string {
+	for _, frame := range err.Stack {
+		if frame.File == err.File && frame.Line == err.Line {
+			return " (in " + frame.Function + ")"
+		}
+	}
+	return ""
+}
+
+// TranslateQuery finds a common command whose keywords all appear in the query
+func (c *OfflineClient) TranslateQuery(ctx context.Context, query, contextInfo string) (*domain.Command, error) {
+	words := make(map[string]bool)
+	for _, word := range strings.FieldsFunc(strings.ToLower(query), func(r rune) bool {
+		return !(r >= 'a' && r <= 'z' || r >= '0' && r <= '9')
+	}) {
+		words[word] = true
+	}
+
+	var best *commandRule
+	for i := range c.kb.commands {
+		rule := &c.kb.commands[i]
+		if rule.matches(words) && (best == nil || len(rule.Keywords) > len(best.Keywords)) {
+			best = rule
+		}
+	}
+	if best == nil {
+		return nil, fmt.Errorf("%w for %q; set up an AI provider for anything beyond common tasks", ErrNoOfflineAnswer, query)
+	}
+
+	platform := detectPlatform(contextInfo)
+	if platform == domain.PlatformUnknown {
+		platform = hostPlatform()
+	}
+
+	command := best.Linux
+	switch platform {
+	case domain.PlatformMacOS:
+		command = best.MacOS
+	case domain.PlatformWindows:
+		command = best.Windows
+	}
+
+	return &domain.Command{
+		Command:     command,
+		Platform:    platform,
+		Context:     contextInfo,
+		Explanation: best.Explanation,
+	}, nil
+}
+
+// Chat can't be answered from the knowledge base, which only holds first answers
+func (c *OfflineClient) Chat(ctx context.Context, err *domain.Error, messages []domain.Message) (string, error) {
+	return "", fmt.Errorf("%w: %w", ErrNoOfflineAnswer, ErrChatUnsupported)
+}
+
+// GetProvider returns the provider name
+func (c *OfflineClient) GetProvider() string {
+	return ProviderOffline
+}
+
+// match finds the rule for err: a pattern under its own type first, then a
+// pattern under any other type (the type guess is rough), then its type's
+// catch-all, then the general one
+func (kb *knowledgeBase) match(err *domain.Error) (*errorRule, map[string]string) {
+	types := append([]string{err.Type}, kb.types...)
+	for _, errorType := range types {
+		for _, rule := range kb.errors[errorType] {
+			if rule.pattern == nil {
+				continue
+			}
+			if data, ok := rule.apply(err.Message); ok {
+				return rule, data
+			}
+		}
+	}
+
+	for _, errorType := range []string{err.Type, domain.ErrorTypeUnknown} {
+		for _, rule := range kb.errors[errorType] {
+			if rule.pattern == nil {
+				data, _ := rule.apply(err.Message)
+				return rule, data
+			}
+		}
+	}
+	return nil, nil
+}
+
+// apply matches message against the rule's pattern and returns the values for its templates
+func (r *errorRule) apply(message string) (map[string]string, bool) {
+	data := map[string]string{"message": message}
+	if r.pattern == nil {
+		return data, true
+	}
+
+	match := r.pattern.FindStringSubmatch(message)
+	if match == nil {
+		return nil, false
+	}
+	for i, name := range r.pattern.SubexpNames() {
+		if name != "" {
+			data[name] = match[i]
+		}
+	}
+	return data, true
+}
+
+// matches reports whether every keyword is one of words
+func (r *commandRule) matches(words map[string]bool) bool {
+	for _, keyword := range r.Keywords {
+		if !words[keyword] {
+			return false
+		}
+	}
+	return len(r.Keywords) > 0
+}
+
+// hostPlatform returns the platform GuruUI is running on
+func hostPlatform() string {
+	switch runtime.GOOS {
+	case "darwin":
+		return domain.PlatformMacOS
+	case "windows":
+		return domain.PlatformWindows
+	default:
+		return domain.PlatformLinux
+	}
+}
+
+// loadKnowledgeBase parses and checks an embedded knowledge file. Every
+// template is tried against its pattern's groups, so a typo in a group name
+// fails here rather than in front of a user.
+func loadKnowledgeBase(name string) (*knowledgeBase, error) {
+	data, err := knowledgeFiles.ReadFile(name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read offline knowledge base: %w", err)
+	}
+
+	var file knowledgeFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse offline knowledge base: %w", err)
+	}
+
+	kb := &knowledgeBase{
+		errors:   make(map[string][]*errorRule),
+		commands: file.Commands,
+	}
+	for errorType, entries := range file.Errors {
+		if !knownErrorTypes[errorType] {
+			return nil, fmt.Errorf("offline knowledge base: unknown error type %q", errorType)
+		}
+		kb.types = append(kb.types, errorType)
+
+		for i, entry := range entries {
+			where := fmt.Sprintf("offline knowledge base: %s rule %d", errorType, i+1)
+
+			rule := &errorRule{}
+			if entry.Pattern != "" {
+				if rule.pattern, err = regexp.Compile(entry.Pattern); err != nil {
+					return nil, fmt.Errorf("%s: %w", where, err)
+				}
+			}
+
+			sample := map[string]string{"message": ""}
+			if rule.pattern != nil {
+				for _, group := range rule.pattern.SubexpNames() {
+					if group != "" {
+						sample[group] = ""
+					}
+				}
+			}
+			for _, t := range []struct {
+				text string
+				dest **template.Template
+			}{
+				{entry.Explanation, &rule.explanation},
+				{entry.Cause, &rule.cause},
+				{entry.Fix, &rule.fix},
+			} {
+				tmpl, err := template.New(errorType).Option("missingkey=error").Parse(t.text)
+				if err != nil {
+					return nil, fmt.Errorf("%s: %w", where, err)
+				}
+				if err := tmpl.Execute(&strings.Builder{}, sample); err != nil {
+					return nil, fmt.Errorf("%s: %w", where, err)
+				}
+				*t.dest = tmpl
+			}
+
+			kb.errors[errorType] = append(kb.errors[errorType], rule)
+		}
+	}
+	sort.Strings(kb.types)
+
+	return kb, nil
+}
@@ -0,0 +1,72 @@
+package ai
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/arnislvdev/go-guru-ui/internal/domain"
+)
+
+func TestOfflineClientExplainsKnownErrors(t *testing.T) {
+	client, err := NewOfflineClient()
+	if err != nil {
+		t.Fatalf("NewOfflineClient returned error: %v", err)
+	}
+
+	tests := []struct {
+		message   string
+		errorType string
+		want      []string
+	}{
+		{"undefined: fmt.Printl", domain.ErrorTypeUndefinedSymbol, []string{"`fmt.Printl`"}},
+		{`"os" imported and not used`, domain.ErrorTypeUnusedImport, []string{"`os`", `_ "os"`}},
+		{"declared and not used: count", domain.ErrorTypeUnusedVariable, []string{"`count`"}},
+		{"x declared but not used", domain.ErrorTypeUnusedVariable, []string{"`x`"}},
+		{"cannot use x (variable of type int) as string value in argument to greet", domain.ErrorTypeTypeMismatch, []string{"`int`", "`string(x)`", "argument to greet"}},
+		{"u.Nmae undefined (type User has no field or method Nmae, but does have field Name)", domain.ErrorTypeUndefinedSymbol, []string{"`Nmae`", "Use `Name`"}},
+		{"too many arguments in call to strings.Split", domain.ErrorTypeArgumentCount, []string{"`strings.Split`", "too many"}},
+		{"missing return", domain.ErrorTypeMissingReturn, []string{"`return`"}},
+		// The type guess is "unknown", but the pattern still finds the rule
+		{"panic: runtime error: index out of range [5] with length 3", domain.ErrorTypeUnknown, []string{"index 5", "only 3"}},
+	}
+
+	for _, tt := range tests {
+		explanation, err := client.ExplainError(context.Background(), &domain.Error{Message: tt.message, Type: tt.errorType})
+		if err != nil {
+			t.Errorf("ExplainError(%q) returned error: %v", tt.message, err)
+			continue
+		}
+		for _, want := range tt.want {
+			if !strings.Contains(explanation, want) {
+				t.Errorf("ExplainError(%q) = %q, want it to contain %q", tt.message, explanation, want)
+			}
+		}
+	}
+}
+
+func TestOfflineClientFallsBackToGeneralAdvice(t *testing.T) {
+	client, _ := NewOfflineClient()
+
+	explanation, err := client.ExplainError(context.Background(), &domain.Error{Message: "something odd", Type: domain.ErrorTypeUnknown})
+	if err != nil || !strings.Contains(explanation, "How to fix it") {
+		t.Errorf("ExplainError = %q, %v; want the general advice", explanation, err)
+	}
+}
+
+func TestOfflineClientTranslatesCommonTasks(t *testing.T) {
+	client, _ := NewOfflineClient()
+
+	command, err := client.TranslateQuery(context.Background(), "how do I check disk space?", "macos")
+	if err != nil {
+		t.Fatalf("TranslateQuery returned error: %v", err)
+	}
+	if command.Command != "df -h" || command.Platform != domain.PlatformMacOS {
+		t.Errorf("command = %+v", command)
+	}
+
+	if _, err := client.TranslateQuery(context.Background(), "write me a poem", ""); !errors.Is(err, ErrNoOfflineAnswer) {
+		t.Errorf("TranslateQuery for an unknown task = %v, want ErrNoOfflineAnswer", err)
+	}
+}
@@ -2,119 +2,133 @@ package ai
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io"
 	"strings"
 
 	"github.com/arnislvdev/go-guru-ui/internal/domain"
 	"github.com/sashabaranov/go-openai"
 )
 
+// defaultOpenAIModel is used when no model is configured
+const defaultOpenAIModel = openai.GPT4
+
 // OpenAIClient implements the AI Client interface using OpenAI
 type OpenAIClient struct {
-	client *openai.Client
-	config *Config
+	client     *openai.Client
+	config     *Config
+	model      string
+	retryAfter *retryAfterTransport
 }
 
-// NewOpenAIClient creates a new OpenAI client
-func NewOpenAIClient() *OpenAIClient {
-	// TODO: Get API key from config/environment
-	apiKey := "your-openai-api-key" // This should come from config
+func init() {
+	Register("openai", func(cfg *Config) (Client, error) {
+		return NewOpenAIClient(cfg)
+	})
+}
 
-	client := openai.NewClient(apiKey)
+// NewOpenAIClient creates a new OpenAI client from the given config
+func NewOpenAIClient(cfg *Config) (*OpenAIClient, error) {
+	if cfg == nil {
+		cfg = DefaultConfig()
+	}
 
-	return &OpenAIClient{
-		client: client,
-		config: DefaultConfig(),
+	clientConfig, err := openAIClientConfig(cfg)
+	if err != nil {
+		return nil, err
 	}
+
+	// go-openai drops response headers from its errors, so catch Retry-After on the way in
+	retryAfter := &retryAfterTransport{base: clientConfig.HTTPClient.Transport}
+	clientConfig.HTTPClient.Transport = retryAfter
+
+	return &OpenAIClient{
+		client:     openai.NewClientWithConfig(clientConfig),
+		config:     cfg,
+		model:      cfg.modelOr(defaultOpenAIModel),
+		retryAfter: retryAfter,
+	}, nil
 }
 
-// ExplainError explains a programming error using OpenAI
-func (c *OpenAIClient) ExplainError(err *domain.Error) (string, error) {
-	prompt := c.buildErrorExplanationPrompt(err)
-
-	resp, apiErr := c.client.CreateChatCompletion(
-		context.Background(),
-		openai.ChatCompletionRequest{
-			Model: c.config.Model,
-			Messages: []openai.ChatCompletionMessage{
-				{
-					Role:    openai.ChatMessageRoleSystem,
-					Content: "You are a helpful programming mentor who explains errors in clear, beginner-friendly terms.",
-				},
-				{
-					Role:    openai.ChatMessageRoleUser,
-					Content: prompt,
-				},
-			},
-			MaxTokens: c.config.MaxTokens,
-		},
-	)
+// openAIClientConfig turns our settings into go-openai's client settings.
+// A custom base URL may point at a gateway that needs no key, so the key is
+// only required for the public API and for Azure.
+func openAIClientConfig(cfg *Config) (openai.ClientConfig, error) {
+	var clientConfig openai.ClientConfig
 
-	if apiErr != nil {
-		return "", fmt.Errorf("OpenAI API error: %w", apiErr)
+	switch apiType := strings.ToLower(cfg.APIType); apiType {
+	case "", "openai":
+		if cfg.APIKey == "" && cfg.BaseURL == "" {
+			return clientConfig, ErrMissingAPIKey
+		}
+		clientConfig = openai.DefaultConfig(cfg.APIKey)
+		if cfg.BaseURL != "" {
+			clientConfig.BaseURL = strings.TrimRight(cfg.BaseURL, "/")
+		}
+	case "azure", "azure_ad":
+		if cfg.APIKey == "" {
+			return clientConfig, ErrMissingAPIKey
+		}
+		if cfg.BaseURL == "" {
+			return clientConfig, fmt.Errorf("api_type %s needs base_url set to your Azure OpenAI endpoint", apiType)
+		}
+		clientConfig = openai.DefaultAzureConfig(cfg.APIKey, strings.TrimRight(cfg.BaseURL, "/"))
+		if apiType == "azure_ad" {
+			clientConfig.APIType = openai.APITypeAzureAD
+		}
+		if cfg.Deployment != "" {
+			deployment := cfg.Deployment
+			clientConfig.AzureModelMapperFunc = func(string) string { return deployment }
+		}
+	default:
+		return clientConfig, fmt.Errorf("unknown api_type %q (use openai, azure or azure_ad)", cfg.APIType)
 	}
 
-	if len(resp.Choices) == 0 {
-		return "", fmt.Errorf("no response from OpenAI")
+	if cfg.APIVersion != "" {
+		clientConfig.APIVersion = cfg.APIVersion
+	}
+	clientConfig.OrgID = cfg.Organization
+	httpClient, err := newHTTPClient(cfg)
+	if err != nil {
+		return clientConfig, err
 	}
+	clientConfig.HTTPClient = httpClient
 
-	return resp.Choices[0].Message.Content, nil
+	return clientConfig, nil
 }
 
-// TranslateQuery converts natural language to CLI commands
-func (c *OpenAIClient) TranslateQuery(query, contextInfo string) (*domain.Command, error) {
-	prompt := c.buildTranslationPrompt(query, contextInfo)
-	resp, apiErr := c.client.CreateChatCompletion(
-		context.Background(),
-		openai.ChatCompletionRequest{
-			Model: c.config.Model,
-			Messages: []openai.ChatCompletionMessage{
-				{
-					Role:    openai.ChatMessageRoleSystem,
-					Content: "You are a CLI expert who translates natural language into executable commands. Return only the command and a brief explanation.",
-				},
-				{
-					Role:    openai.ChatMessageRoleUser,
-					Content: prompt,
-				},
-			},
-			MaxTokens: c.config.MaxTokens,
-		},
-	)
-
-	if apiErr != nil {
-		return nil, fmt.Errorf("OpenAI API error: %w", apiErr)
+// ExplainError explains a programming error using OpenAI
+func (c *OpenAIClient) ExplainError(ctx context.Context, err *domain.Error) (string, error) {
+	req, promptErr := c.config.prompts().explainRequest(err)
+	if promptErr != nil {
+		return "", promptErr
 	}
+	return c.complete(ctx, req)
+}
 
-	if len(resp.Choices) == 0 {
-		return nil, fmt.Errorf("no response from OpenAI")
+// ExplainErrorStream explains a programming error, passing text to onChunk as it arrives
+func (c *OpenAIClient) ExplainErrorStream(ctx context.Context, err *domain.Error, onChunk func(string)) (string, error) {
+	req, promptErr := c.config.prompts().explainRequest(err)
+	if promptErr != nil {
+		return "", promptErr
 	}
+	req.OnChunk = onChunk
+	return c.complete(ctx, req)
+}
 
-	// Parse the response to extract command and explanation
-	// This is a simplified parser - in production, you'd want more robust parsing
-	response := resp.Choices[0].Message.Content
-	lines := strings.Split(response, "\n")
-
-	var command string
-	var explanation string
-
-	for _, line := range lines {
-		line = strings.TrimSpace(line)
-		if strings.HasPrefix(line, "Command:") {
-			command = strings.TrimPrefix(line, "Command:")
-			command = strings.TrimSpace(command)
-		} else if strings.HasPrefix(line, "Explanation:") {
-			explanation = strings.TrimPrefix(line, "Explanation:")
-			explanation = strings.TrimSpace(explanation)
-		}
+// Chat answers a follow-up question about err using OpenAI
+func (c *OpenAIClient) Chat(ctx context.Context, err *domain.Error, messages []domain.Message) (string, error) {
+	req, promptErr := c.config.prompts().chatRequest(ctx, err, messages)
+	if promptErr != nil {
+		return "", promptErr
 	}
+	return c.complete(ctx, req)
+}
 
-	return &domain.Command{
-		Command:     command,
-		Explanation: explanation,
-		Platform:    c.detectPlatform(contextInfo),
-		Context:     contextInfo,
-	}, nil
+// TranslateQuery converts natural language to CLI commands
+func (c *OpenAIClient) TranslateQuery(ctx context.Context, query, contextInfo string) (*domain.Command, error) {
+	return translateQuery(ctx, c, c.config.prompts(), query, contextInfo)
 }
 
 // GetProvider returns the provider name
@@ -122,52 +136,148 @@ func (c *OpenAIClient) GetProvider() string {
 	return "openai"
 }
 
-// buildErrorExplanationPrompt creates a prompt for error explanation
-func (c *OpenAIClient) buildErrorExplanationPrompt(err *domain.Error) string {
-	prompt := fmt.Sprintf(`Explain this %s programming error in clear, beginner-friendly terms:
+// complete sends a chat completion request and returns the reply
+func (c *OpenAIClient) complete(ctx context.Context, req completion) (string, error) {
+	ctx, cancel := c.config.withTimeout(ctx)
+	defer cancel()
 
-Error: %s
-Type: %s
-Severity: %s
-Language: %s`, err.Severity, err.Message, err.Type, err.Severity, err.Language)
+	model := c.config.modelFor(req.Task, c.model)
 
-	if err.File != "" {
-		prompt += fmt.Sprintf("\nFile: %s", err.File)
+	if req.OnChunk != nil {
+		text, err := c.stream(ctx, c.request(req, model), req.OnChunk)
+		if err == nil {
+			// This version of the streaming API doesn't report usage
+			reportUsage(ctx, estimateUsage(c.GetProvider(), model, req, text))
+		}
+		return text, err
 	}
-	if err.Line > 0 {
-		prompt += fmt.Sprintf("\nLine: %d", err.Line)
+
+	resp, apiErr := c.client.CreateChatCompletion(ctx, c.request(req, model))
+
+	if apiErr != nil {
+		if err := c.config.contextError(ctx, c.GetProvider()); err != nil {
+			return "", err
+		}
+		return "", c.apiError(apiErr)
+	}
+
+	reportUsage(ctx, Usage{
+		Provider:     c.GetProvider(),
+		Model:        model,
+		InputTokens:  resp.Usage.PromptTokens,
+		OutputTokens: resp.Usage.CompletionTokens,
+	})
+
+	if len(resp.Choices) == 0 {
+		return "", fmt.Errorf("no response from OpenAI")
 	}
 
-	prompt += "\n\nProvide a clear explanation and suggest how to fix it."
-	return prompt
+	return resp.Choices[0].Message.Content, nil
 }
 
-// buildTranslationPrompt creates a prompt for command translation
-func (c *OpenAIClient) buildTranslationPrompt(query, context string) string {
-	prompt := fmt.Sprintf(`Translate this natural language request into a CLI command:
+// stream sends a streaming chat completion request, passing text to onChunk as it arrives
+func (c *OpenAIClient) stream(ctx context.Context, request openai.ChatCompletionRequest, onChunk func(string)) (string, error) {
+	stream, apiErr := c.client.CreateChatCompletionStream(ctx, request)
+	if apiErr != nil {
+		if err := c.config.contextError(ctx, c.GetProvider()); err != nil {
+			return "", err
+		}
+		return "", c.apiError(apiErr)
+	}
+	defer stream.Close()
+
+	var text strings.Builder
+	for {
+		resp, recvErr := stream.Recv()
+		if errors.Is(recvErr, io.EOF) {
+			break
+		}
+		if recvErr != nil {
+			if err := c.config.contextError(ctx, c.GetProvider()); err != nil {
+				return "", err
+			}
+			return "", c.apiError(recvErr)
+		}
+		if len(resp.Choices) == 0 || resp.Choices[0].Delta.Content == "" {
+			continue
+		}
+
+		chunk := resp.Choices[0].Delta.Content
+		text.WriteString(chunk)
+		onChunk(chunk)
+	}
 
-Request: %s`, query)
+	if text.Len() == 0 {
+		return "", fmt.Errorf("no response from OpenAI")
+	}
+
+	return text.String(), nil
+}
+
+// request builds a chat completion request for model from a completion
+func (c *OpenAIClient) request(req completion, model string) openai.ChatCompletionRequest {
+	messages := []openai.ChatCompletionMessage{
+		{
+			Role:    openai.ChatMessageRoleSystem,
+			Content: req.System,
+		},
+	}
+	for _, message := range req.Messages {
+		messages = append(messages, openai.ChatCompletionMessage{
+			Role:    message.Role,
+			Content: message.Content,
+		})
+	}
 
-	if context != "" {
-		prompt += fmt.Sprintf("\nContext: %s", context)
+	request := openai.ChatCompletionRequest{
+		Model:     model,
+		Messages:  messages,
+		MaxTokens: c.config.MaxTokens,
 	}
+	if req.JSON && supportsJSONMode(model) {
+		request.ResponseFormat = &openai.ChatCompletionResponseFormat{
+			Type: openai.ChatCompletionResponseFormatTypeJSONObject,
+		}
+	}
+	return request
+}
 
-	prompt += "\n\nRespond with:\nCommand: <the actual command>\nExplanation: <brief explanation of what it does>"
-	return prompt
+// jsonModeModels are model name prefixes known to accept response_format json_object.
+// Others (like the original gpt-4) reject the request, so they get the plain prompt.
+var jsonModeModels = []string{"gpt-3.5-turbo", "gpt-4-turbo", "gpt-4-1106", "gpt-4-0125", "gpt-4o", "gpt-4.1", "gpt-5", "o1", "o3", "o4"}
+
+// supportsJSONMode reports whether model can be asked for a JSON object
+func supportsJSONMode(model string) bool {
+	for _, prefix := range jsonModeModels {
+		if strings.HasPrefix(model, prefix) {
+			return true
+		}
+	}
+	return false
 }
 
-// detectPlatform attempts to detect the platform from context
-func (c *OpenAIClient) detectPlatform(context string) string {
-	context = strings.ToLower(context)
+// apiError turns a go-openai error into an *APIError carrying the HTTP status
+func (c *OpenAIClient) apiError(err error) error {
+	apiErr := &APIError{Provider: "OpenAI", Message: err.Error(), Err: err}
 
+	var openaiErr *openai.APIError
+	var requestErr *openai.RequestError
 	switch {
-	case strings.Contains(context, "linux") || strings.Contains(context, "ubuntu") || strings.Contains(context, "debian"):
-		return domain.PlatformLinux
-	case strings.Contains(context, "macos") || strings.Contains(context, "mac") || strings.Contains(context, "darwin"):
-		return domain.PlatformMacOS
-	case strings.Contains(context, "windows") || strings.Contains(context, "win"):
-		return domain.PlatformWindows
+	case errors.As(err, &openaiErr):
+		apiErr.StatusCode = openaiErr.HTTPStatusCode
+		apiErr.Message = openaiErr.Message
+	case errors.As(err, &requestErr):
+		apiErr.StatusCode = requestErr.HTTPStatusCode
+		if requestErr.Err != nil {
+			apiErr.Message = requestErr.Err.Error()
+		}
 	default:
-		return domain.PlatformUnknown
+		// Not an HTTP answer (e.g. connection refused): keep the original error
+		return fmt.Errorf("OpenAI API error: %w", err)
+	}
+
+	if apiErr.Temporary() {
+		apiErr.RetryAfter = c.retryAfter.last()
 	}
+	return apiErr
 }
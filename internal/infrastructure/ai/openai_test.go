@@ -0,0 +1,98 @@
+package ai
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/arnislvdev/go-guru-ui/internal/domain"
+)
+
+const openAITestReply = `{"choices":[{"index":0,"message":{"role":"assistant","content":"Import fmt."},"finish_reason":"stop"}]}`
+
+func TestOpenAICustomBaseURLAndHeaders(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/chat/completions" {
+			t.Errorf("path = %s, want /v1/chat/completions", r.URL.Path)
+		}
+		if org := r.Header.Get("OpenAI-Organization"); org != "org-team" {
+			t.Errorf("OpenAI-Organization = %q, want org-team", org)
+		}
+		if tenant := r.Header.Get("X-Tenant"); tenant != "platform" {
+			t.Errorf("X-Tenant = %q, want platform", tenant)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(openAITestReply))
+	}))
+	defer server.Close()
+
+	// No API key: a gateway at a custom URL may not need one
+	client, err := NewOpenAIClient(&Config{
+		BaseURL:      server.URL + "/v1",
+		Organization: "org-team",
+		Headers:      map[string]string{"X-Tenant": "platform"},
+		MaxTokens:    10,
+	})
+	if err != nil {
+		t.Fatalf("NewOpenAIClient returned error: %v", err)
+	}
+
+	explanation, err := client.ExplainError(context.Background(), &domain.Error{Message: "undefined: fmt"})
+	if err != nil {
+		t.Fatalf("ExplainError returned error: %v", err)
+	}
+	if explanation != "Import fmt." {
+		t.Errorf("explanation = %q", explanation)
+	}
+}
+
+func TestOpenAIAzureDeployment(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/openai/deployments/team-gpt4/chat/completions" {
+			t.Errorf("path = %s, want the team-gpt4 deployment", r.URL.Path)
+		}
+		if version := r.URL.Query().Get("api-version"); version != "2024-02-01" {
+			t.Errorf("api-version = %q, want 2024-02-01", version)
+		}
+		if key := r.Header.Get("api-key"); key != "azure-key" {
+			t.Errorf("api-key = %q, want azure-key", key)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(openAITestReply))
+	}))
+	defer server.Close()
+
+	client, err := NewOpenAIClient(&Config{
+		APIKey:     "azure-key",
+		BaseURL:    server.URL,
+		APIType:    "azure",
+		APIVersion: "2024-02-01",
+		Deployment: "team-gpt4",
+		MaxTokens:  10,
+	})
+	if err != nil {
+		t.Fatalf("NewOpenAIClient returned error: %v", err)
+	}
+
+	if _, err := client.ExplainError(context.Background(), &domain.Error{Message: "undefined: fmt"}); err != nil {
+		t.Fatalf("ExplainError returned error: %v", err)
+	}
+}
+
+func TestOpenAIClientConfigErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  *Config
+	}{
+		{"no key for public API", &Config{}},
+		{"azure without base URL", &Config{APIKey: "k", APIType: "azure"}},
+		{"unknown api type", &Config{APIKey: "k", APIType: "bedrock"}},
+	}
+
+	for _, test := range tests {
+		if _, err := NewOpenAIClient(test.cfg); err == nil {
+			t.Errorf("%s: NewOpenAIClient should fail", test.name)
+		}
+	}
+}
@@ -0,0 +1,247 @@
+package ai
+
+import (
+	"context"
+	"embed"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"text/template"
+
+	"github.com/arnislvdev/go-guru-ui/internal/domain"
+)
+
+// chatMessage is one turn of a conversation, in the role/content shape most chat APIs share
+type chatMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+// Prompt template names. Each is a file <name>.tmpl, built in or in the prompts directory.
+const (
+	PromptExplainSystem   = "explain_system"
+	PromptExplain         = "explain"
+	PromptTranslateSystem = "translate_system"
+	PromptTranslate       = "translate"
+)
+
+// promptExt is the file extension of prompt templates
+const promptExt = ".tmpl"
+
+//go:embed prompts/*.tmpl
+var builtinPrompts embed.FS
+
+// TranslationInput is what the translate templates see
+type TranslationInput struct {
+	Query    string
+	Context  string
+	Platform string // guessed from Context: linux, macos, windows or unknown
+}
+
+// Prompts holds the parsed prompt templates. The explain templates see the
+// *domain.Error being explained; the translate templates see a TranslationInput.
+type Prompts struct {
+	templates map[string]*template.Template
+	text      map[string]string
+	origin    map[string]string // "built-in" or the file it was read from
+}
+
+var (
+	defaultPromptsOnce sync.Once
+	defaultPrompts     *Prompts
+)
+
+// PromptNames returns the names of every prompt template
+func PromptNames() []string {
+	return []string{PromptExplainSystem, PromptExplain, PromptTranslateSystem, PromptTranslate}
+}
+
+// DefaultPrompts returns the built-in prompts
+func DefaultPrompts() *Prompts {
+	defaultPromptsOnce.Do(func() {
+		p, err := LoadPrompts("")
+		if err != nil {
+			panic(fmt.Sprintf("ai: built-in prompts are broken: %v", err))
+		}
+		defaultPrompts = p
+	})
+	return defaultPrompts
+}
+
+// LoadPrompts returns the built-in prompts with any <name>.tmpl files in dir
+// in their place. An empty or missing dir means no overrides. Every template
+// is rendered once with sample data, so mistakes show up here rather than in
+// the middle of a request.
+func LoadPrompts(dir string) (*Prompts, error) {
+	p := &Prompts{
+		templates: make(map[string]*template.Template),
+		text:      make(map[string]string),
+		origin:    make(map[string]string),
+	}
+
+	for _, name := range PromptNames() {
+		data, err := builtinPrompts.ReadFile("prompts/" + name + promptExt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read built-in prompt %s: %w", name, err)
+		}
+		p.text[name], p.origin[name] = string(data), "built-in"
+	}
+
+	if dir != "" {
+		if err := p.readOverrides(dir); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, name := range PromptNames() {
+		tmpl, err := template.New(name).Option("missingkey=error").Parse(p.text[name])
+		if err != nil {
+			return nil, fmt.Errorf("invalid prompt template %s: %w", p.origin[name], err)
+		}
+		p.templates[name] = tmpl
+
+		if _, err := p.Render(name, SamplePromptData(name)); err != nil {
+			return nil, fmt.Errorf("invalid prompt template %s: %w", p.origin[name], err)
+		}
+	}
+	return p, nil
+}
+
+// readOverrides replaces built-in text with the templates found in dir
+func (p *Prompts) readOverrides(dir string) error {
+	entries, err := os.ReadDir(dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read prompts directory: %w", err)
+	}
+
+	for _, entry := range entries {
+		name, ok := strings.CutSuffix(entry.Name(), promptExt)
+		if entry.IsDir() || !ok {
+			continue
+		}
+		path := filepath.Join(dir, entry.Name())
+		if _, known := p.text[name]; !known {
+			return fmt.Errorf("unknown prompt template %s (valid names: %s)", path, strings.Join(PromptNames(), ", "))
+		}
+
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("failed to read prompt template: %w", err)
+		}
+		p.text[name], p.origin[name] = string(data), path
+	}
+	return nil
+}
+
+// Text returns a template's source and where it came from
+func (p *Prompts) Text(name string) (text, origin string, ok bool) {
+	text, ok = p.text[name]
+	return text, p.origin[name], ok
+}
+
+// Render fills in the named template. Surrounding whitespace is trimmed, so
+// template files may end with a newline.
+func (p *Prompts) Render(name string, data any) (string, error) {
+	tmpl, ok := p.templates[name]
+	if !ok {
+		return "", fmt.Errorf("unknown prompt template %q", name)
+	}
+
+	var out strings.Builder
+	if err := tmpl.Execute(&out, data); err != nil {
+		return "", fmt.Errorf("failed to render prompt %s: %w", name, err)
+	}
+	return strings.TrimSpace(out.String()), nil
+}
+
+// SamplePromptData returns example data of the right kind for the named template
+func SamplePromptData(name string) any {
+	if strings.HasPrefix(name, "translate") {
+		return NewTranslationInput("find files larger than 100MB", "Ubuntu 22.04")
+	}
+	return &domain.Error{
+		Message:  "undefined: fmt",
+		Type:     domain.ErrorTypeUndefinedSymbol,
+		File:     "main.go",
+		Line:     12,
+		Severity: domain.SeverityError,
+		Language: domain.LanguageGo,
+	}
+}
+
+// NewTranslationInput returns the template data for a translation request
+func NewTranslationInput(query, contextInfo string) TranslationInput {
+	return TranslationInput{Query: query, Context: contextInfo, Platform: detectPlatform(contextInfo)}
+}
+
+// explainRequest renders the explain templates for err
+func (p *Prompts) explainRequest(err *domain.Error) (completion, error) {
+	return p.request(TaskExplain, PromptExplainSystem, PromptExplain, err)
+}
+
+// chatRequest renders the explain templates for err and adds the conversation
+// after it. A message in the same role as the one before it is joined to it,
+// since not every provider accepts two turns in a row from one side. The
+// model is the one for TaskChat unless ctx names another task.
+func (p *Prompts) chatRequest(ctx context.Context, err *domain.Error, messages []domain.Message) (completion, error) {
+	req, renderErr := p.explainRequest(err)
+	if renderErr != nil {
+		return completion{}, renderErr
+	}
+	req.Task = chatTask(ctx)
+	for _, message := range messages {
+		if last := len(req.Messages) - 1; req.Messages[last].Role == message.Role {
+			req.Messages[last].Content += "\n\n" + message.Content
+			continue
+		}
+		req.Messages = append(req.Messages, chatMessage{Role: message.Role, Content: message.Content})
+	}
+	return req, nil
+}
+
+// translateRequest renders the translate templates for a query
+func (p *Prompts) translateRequest(query, contextInfo string) (completion, error) {
+	return p.request(TaskTranslate, PromptTranslateSystem, PromptTranslate, NewTranslationInput(query, contextInfo))
+}
+
+// request renders a system and user template into a completion for task
+func (p *Prompts) request(task, systemName, userName string, data any) (completion, error) {
+	system, err := p.Render(systemName, data)
+	if err != nil {
+		return completion{}, err
+	}
+	prompt, err := p.Render(userName, data)
+	if err != nil {
+		return completion{}, err
+	}
+	req := userPrompt(system, prompt)
+	req.Task = task
+	return req, nil
+}
+
+// buildRepairPrompt asks the model to fix an answer that didn't parse
+func buildRepairPrompt(problem error) string {
+	return fmt.Sprintf("That answer could not be used: %v. Reply again with only the JSON object described above, with no other text.", problem)
+}
+
+// detectPlatform attempts to detect the platform from context
+func detectPlatform(context string) string {
+	context = strings.ToLower(context)
+
+	switch {
+	case strings.Contains(context, "linux") || strings.Contains(context, "ubuntu") || strings.Contains(context, "debian"):
+		return domain.PlatformLinux
+	case strings.Contains(context, "macos") || strings.Contains(context, "mac") || strings.Contains(context, "darwin"):
+		return domain.PlatformMacOS
+	case strings.Contains(context, "windows") || strings.Contains(context, "win"):
+		return domain.PlatformWindows
+	default:
+		return domain.PlatformUnknown
+	}
+}
@@ -0,0 +1,147 @@
+package ai
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/arnislvdev/go-guru-ui/internal/domain"
+)
+
+// defaultExplainSystem returns the built-in explain system message
+func defaultExplainSystem(t *testing.T) string {
+	t.Helper()
+
+	system, err := DefaultPrompts().Render(PromptExplainSystem, &domain.Error{})
+	if err != nil {
+		t.Fatalf("Render returned error: %v", err)
+	}
+	return system
+}
+
+func TestDefaultExplainPrompt(t *testing.T) {
+	req, err := DefaultPrompts().explainRequest(&domain.Error{
+		Message:  "undefined: fmt",
+		Type:     domain.ErrorTypeUndefinedSymbol,
+		Severity: domain.SeverityError,
+		Language: domain.LanguageGo,
+		File:     "main.go",
+	})
+	if err != nil {
+		t.Fatalf("explainRequest returned error: %v", err)
+	}
+
+	want := "Explain this error programming error in clear, beginner-friendly terms:\n\n" +
+		"Error: undefined: fmt\nType: undefined_symbol\nSeverity: error\nLanguage: go\nFile: main.go\n\n" +
+		"Provide a clear explanation and suggest how to fix it."
+	if len(req.Messages) != 1 || req.Messages[0].Content != want {
+		t.Errorf("prompt = %q, want %q", req.Messages, want)
+	}
+	if !strings.Contains(req.System, "programming mentor") {
+		t.Errorf("system = %q", req.System)
+	}
+}
+
+func TestExplainPromptWithStack(t *testing.T) {
+	req, err := DefaultPrompts().explainRequest(&domain.Error{
+		Message:     "panic: assignment to entry in nil map",
+		Type:        domain.ErrorTypeRuntimePanic,
+		Severity:    domain.SeverityFatal,
+		Language:    domain.LanguageGo,
+		File:        "store/index.go",
+		Line:        42,
+		RuntimeKind: domain.RuntimeNilMapWrite,
+		Stack: []domain.Frame{
+			{Function: "runtime.mapassign_faststr", File: "/usr/local/go/src/runtime/map_faststr.go", Line: 203},
+			{Function: "example.com/app/store.(*Index).Add", File: "store/index.go", Line: 42, User: true},
+		},
+	})
+	if err != nil {
+		t.Fatalf("explainRequest returned error: %v", err)
+	}
+
+	for _, want := range []string{
+		"Runtime error: nil_map_write",
+		"  runtime.mapassign_faststr (/usr/local/go/src/runtime/map_faststr.go:203)\n",
+		"  example.com/app/store.(*Index).Add (store/index.go:42) [user code]",
+		"Explain the failure at store/index.go:42",
+	} {
+		if !strings.Contains(req.Messages[0].Content, want) {
+			t.Errorf("prompt = %q, want it to contain %q", req.Messages[0].Content, want)
+		}
+	}
+}
+
+func TestLoadPromptsOverrides(t *testing.T) {
+	dir := t.TempDir()
+	override := "We use {{.Platform}} only.\nRequest: {{.Query}}\n"
+	if err := os.WriteFile(filepath.Join(dir, "translate.tmpl"), []byte(override), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	prompts, err := LoadPrompts(dir)
+	if err != nil {
+		t.Fatalf("LoadPrompts returned error: %v", err)
+	}
+
+	req, err := prompts.translateRequest("list files", "on my mac")
+	if err != nil {
+		t.Fatalf("translateRequest returned error: %v", err)
+	}
+	if req.Messages[0].Content != "We use macos only.\nRequest: list files" {
+		t.Errorf("prompt = %q", req.Messages[0].Content)
+	}
+	if _, origin, _ := prompts.Text(PromptTranslate); origin != filepath.Join(dir, "translate.tmpl") {
+		t.Errorf("origin = %q", origin)
+	}
+	if _, origin, _ := prompts.Text(PromptExplain); origin != "built-in" {
+		t.Errorf("explain origin = %q, want built-in", origin)
+	}
+}
+
+func TestLoadPromptsRejectsBadTemplates(t *testing.T) {
+	tests := map[string]string{
+		"explain.tmpl":   "Error: {{.Mesage}}", // no such field
+		"translate.tmpl": "Request: {{.Query",  // doesn't parse
+		"explian.tmpl":   "typo in the name",
+	}
+
+	for file, text := range tests {
+		dir := t.TempDir()
+		if err := os.WriteFile(filepath.Join(dir, file), []byte(text), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := LoadPrompts(dir); err == nil || !strings.Contains(err.Error(), file) {
+			t.Errorf("LoadPrompts with %s = %v, want an error naming the file", file, err)
+		}
+	}
+}
+
+func TestLoadPromptsMissingDir(t *testing.T) {
+	if _, err := LoadPrompts(filepath.Join(t.TempDir(), "nothing-here")); err != nil {
+		t.Errorf("a missing prompts directory should mean no overrides, got %v", err)
+	}
+}
+
+func TestChatRequestJoinsTurns(t *testing.T) {
+	req, err := DefaultPrompts().chatRequest(context.Background(), &domain.Error{Message: "undefined: fmt"}, []domain.Message{
+		{Role: domain.RoleUser, Content: "You may use tools."},
+		{Role: domain.RoleAssistant, Content: "Import fmt."},
+	})
+	if err != nil {
+		t.Fatalf("chatRequest returned error: %v", err)
+	}
+	if req.Task != TaskChat || len(req.Messages) != 2 {
+		t.Fatalf("request = %+v, want the task chat and two turns", req)
+	}
+	if !strings.HasSuffix(req.Messages[0].Content, "\n\nYou may use tools.") || req.Messages[1].Role != "assistant" {
+		t.Errorf("messages = %+v, want the two user turns joined", req.Messages)
+	}
+
+	req, err = DefaultPrompts().chatRequest(WithChatTask(context.Background(), TaskExplain), &domain.Error{Message: "undefined: fmt"}, nil)
+	if err != nil || req.Task != TaskExplain {
+		t.Errorf("chatRequest with TaskExplain in the context = %q, %v, want the task explain", req.Task, err)
+	}
+}
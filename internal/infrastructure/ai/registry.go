@@ -0,0 +1,61 @@
+package ai
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Constructor builds a Client for one provider from config
+type Constructor func(cfg *Config) (Client, error)
+
+var (
+	registryMu sync.RWMutex
+	registry   = make(map[string]Constructor)
+)
+
+// Register makes a provider available under name.
+// It panics if the name is empty or already taken, since that is a programming mistake.
+func Register(name string, ctor Constructor) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	if name == "" || ctor == nil {
+		panic("ai: Register needs a name and a constructor")
+	}
+	if _, exists := registry[name]; exists {
+		panic(fmt.Sprintf("ai: provider %q registered twice", name))
+	}
+	registry[name] = ctor
+}
+
+// Providers returns the names of all registered providers, sorted
+func Providers() []string {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	names := make([]string, 0, len(registry))
+	for name := range registry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// New builds the Client for the provider named in cfg
+func New(cfg *Config) (Client, error) {
+	if cfg == nil {
+		cfg = DefaultConfig()
+	}
+
+	registryMu.RLock()
+	ctor, ok := registry[cfg.Provider]
+	registryMu.RUnlock()
+
+	if !ok {
+		return nil, fmt.Errorf("unknown AI provider %q (valid providers: %s)", cfg.Provider, strings.Join(Providers(), ", "))
+	}
+
+	return ctor(cfg)
+}
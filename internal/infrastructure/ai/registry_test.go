@@ -0,0 +1,48 @@
+package ai
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestNewKnownProviders(t *testing.T) {
+	for _, name := range []string{"openai", "anthropic"} {
+		client, err := New(&Config{Provider: name, APIKey: "test-key", MaxTokens: 10})
+		if err != nil {
+			t.Errorf("New(%s) returned error: %v", name, err)
+			continue
+		}
+		if client.GetProvider() != name {
+			t.Errorf("New(%s).GetProvider() = %s", name, client.GetProvider())
+		}
+	}
+}
+
+func TestNewUnknownProvider(t *testing.T) {
+	_, err := New(&Config{Provider: "skynet"})
+	if err == nil {
+		t.Fatal("New with unknown provider should fail")
+	}
+	for _, name := range Providers() {
+		if !strings.Contains(err.Error(), name) {
+			t.Errorf("error %q should list provider %s", err, name)
+		}
+	}
+}
+
+func TestNewMissingKey(t *testing.T) {
+	_, err := New(&Config{Provider: "openai"})
+	if !errors.Is(err, ErrMissingAPIKey) {
+		t.Errorf("New without key = %v, want ErrMissingAPIKey", err)
+	}
+}
+
+func TestRegisterDuplicatePanics(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("registering openai twice should panic")
+		}
+	}()
+	Register("openai", func(cfg *Config) (Client, error) { return nil, nil })
+}
@@ -0,0 +1,192 @@
+package ai
+
+import (
+	"context"
+	"math/rand"
+	"time"
+
+	"github.com/arnislvdev/go-guru-ui/internal/domain"
+)
+
+// RetryPolicy controls how RetryClient retries failed requests
+type RetryPolicy struct {
+	MaxAttempts int           // total tries, including the first
+	BaseDelay   time.Duration // wait before the first retry; doubles each time
+	MaxDelay    time.Duration // longest single wait
+	MaxElapsed  time.Duration // give up once this much time has passed; zero means no limit
+}
+
+// DefaultRetryPolicy returns the retry settings used when none are configured
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts: 4,
+		BaseDelay:   500 * time.Millisecond,
+		MaxDelay:    20 * time.Second,
+		MaxElapsed:  90 * time.Second,
+	}
+}
+
+// RetryClient wraps any Client and retries rate limits, server errors and
+// dropped connections with jittered exponential backoff
+type RetryClient struct {
+	client Client
+	policy RetryPolicy
+
+	// OnRetry, if set, is called before each retry with the upcoming attempt
+	// number (2 for the first retry), the wait, and the error being retried
+	OnRetry func(attempt int, wait time.Duration, err error)
+
+	retries int
+	sleep   func(ctx context.Context, d time.Duration) error
+	jitter  func() float64
+}
+
+// NewRetryClient wraps client so that passing failures are retried under policy
+func NewRetryClient(client Client, policy RetryPolicy) *RetryClient {
+	if policy.MaxAttempts < 1 {
+		policy.MaxAttempts = 1
+	}
+
+	return &RetryClient{
+		client: client,
+		policy: policy,
+		sleep:  sleepContext,
+		jitter: rand.Float64,
+	}
+}
+
+// ExplainError explains a programming error, retrying passing failures
+func (r *RetryClient) ExplainError(ctx context.Context, err *domain.Error) (string, error) {
+	var explanation string
+	retryErr := r.do(ctx, func() error {
+		var callErr error
+		explanation, callErr = r.client.ExplainError(ctx, err)
+		return callErr
+	})
+	return explanation, retryErr
+}
+
+// ExplainErrorStream streams an explanation. Once text has reached onChunk a
+// failure is returned as is, since retrying would print the start twice.
+func (r *RetryClient) ExplainErrorStream(ctx context.Context, err *domain.Error, onChunk func(string)) (string, error) {
+	var explanation string
+	started := false
+
+	retryErr := r.do(ctx, func() error {
+		var callErr error
+		explanation, callErr = StreamExplanation(ctx, r.client, err, func(chunk string) {
+			started = true
+			onChunk(chunk)
+		})
+		if callErr != nil && started {
+			return permanent{callErr}
+		}
+		return callErr
+	})
+	return explanation, retryErr
+}
+
+// TranslateQuery converts natural language to CLI commands, retrying passing failures
+func (r *RetryClient) TranslateQuery(ctx context.Context, query, contextInfo string) (*domain.Command, error) {
+	var command *domain.Command
+	retryErr := r.do(ctx, func() error {
+		var callErr error
+		command, callErr = r.client.TranslateQuery(ctx, query, contextInfo)
+		return callErr
+	})
+	return command, retryErr
+}
+
+// Chat answers a follow-up question, retrying passing failures
+func (r *RetryClient) Chat(ctx context.Context, err *domain.Error, messages []domain.Message) (string, error) {
+	var reply string
+	retryErr := r.do(ctx, func() error {
+		var callErr error
+		reply, callErr = r.client.Chat(ctx, err, messages)
+		return callErr
+	})
+	return reply, retryErr
+}
+
+// GetProvider returns the wrapped client's provider name
+func (r *RetryClient) GetProvider() string {
+	return r.client.GetProvider()
+}
+
+// Retries returns how many retries this client has made so far
+func (r *RetryClient) Retries() int {
+	return r.retries
+}
+
+// do runs call until it succeeds, fails for good, or the policy runs out
+func (r *RetryClient) do(ctx context.Context, call func() error) error {
+	start := time.Now()
+
+	for attempt := 1; ; attempt++ {
+		err := call()
+		if p, ok := err.(permanent); ok {
+			return p.err
+		}
+		if err == nil || !IsRetryable(err) || attempt >= r.policy.MaxAttempts {
+			return err
+		}
+
+		// A provider that asks for a longer wait than MaxDelay is given up on, so a fallback can answer
+		if asked := retryAfter(err); r.policy.MaxDelay > 0 && asked > r.policy.MaxDelay {
+			return err
+		}
+
+		wait := r.backoff(attempt, err)
+		if r.policy.MaxElapsed > 0 && time.Since(start)+wait > r.policy.MaxElapsed {
+			return err
+		}
+
+		if r.OnRetry != nil {
+			r.OnRetry(attempt+1, wait, err)
+		}
+		if sleepErr := r.sleep(ctx, wait); sleepErr != nil {
+			return ErrCanceled
+		}
+		r.retries++
+	}
+}
+
+// backoff picks the wait before the next try: what the provider asked for if
+// it said, otherwise BaseDelay doubled per attempt, with jitter and capped at
+// MaxDelay. do gives up rather than wait on a Retry-After longer than MaxDelay.
+func (r *RetryClient) backoff(attempt int, err error) time.Duration {
+	if wait := retryAfter(err); wait > 0 {
+		return wait
+	}
+
+	wait := r.policy.BaseDelay << (attempt - 1)
+	if wait <= 0 || (r.policy.MaxDelay > 0 && wait > r.policy.MaxDelay) {
+		wait = r.policy.MaxDelay
+	}
+
+	// Equal jitter: somewhere between half and all of the wait
+	half := wait / 2
+	return half + time.Duration(r.jitter()*float64(half))
+}
+
+// permanent marks an error that must not be retried whatever its kind
+type permanent struct {
+	err error
+}
+
+func (p permanent) Error() string {
+	return p.err.Error()
+}
+
+// sleepContext waits for d, or returns early with ctx's error
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
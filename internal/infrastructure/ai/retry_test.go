@@ -0,0 +1,236 @@
+package ai
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/arnislvdev/go-guru-ui/internal/domain"
+)
+
+// flakyClient fails with the queued errors, one per call, then answers
+type flakyClient struct {
+	errs  []error
+	calls int
+}
+
+func (c *flakyClient) next() error {
+	c.calls++
+	if len(c.errs) == 0 {
+		return nil
+	}
+	err := c.errs[0]
+	c.errs = c.errs[1:]
+	return err
+}
+
+func (c *flakyClient) ExplainError(ctx context.Context, err *domain.Error) (string, error) {
+	if callErr := c.next(); callErr != nil {
+		return "", callErr
+	}
+	return "explained", nil
+}
+
+func (c *flakyClient) TranslateQuery(ctx context.Context, query, contextInfo string) (*domain.Command, error) {
+	if err := c.next(); err != nil {
+		return nil, err
+	}
+	return &domain.Command{Command: "ls"}, nil
+}
+
+func (c *flakyClient) Chat(ctx context.Context, err *domain.Error, messages []domain.Message) (string, error) {
+	if callErr := c.next(); callErr != nil {
+		return "", callErr
+	}
+	return "replied", nil
+}
+
+func (c *flakyClient) GetProvider() string {
+	return "flaky"
+}
+
+// newTestRetryClient returns a RetryClient that records waits instead of sleeping
+func newTestRetryClient(client Client, policy RetryPolicy) (*RetryClient, *[]time.Duration) {
+	var waits []time.Duration
+	retry := NewRetryClient(client, policy)
+	retry.sleep = func(ctx context.Context, d time.Duration) error {
+		waits = append(waits, d)
+		return ctx.Err()
+	}
+	retry.jitter = func() float64 { return 1 }
+	return retry, &waits
+}
+
+func TestRetryClientRetriesTransientErrors(t *testing.T) {
+	flaky := &flakyClient{errs: []error{
+		&APIError{Provider: "flaky", StatusCode: http.StatusTooManyRequests},
+		&APIError{Provider: "flaky", StatusCode: http.StatusBadGateway},
+	}}
+	retry, waits := newTestRetryClient(flaky, RetryPolicy{MaxAttempts: 5, BaseDelay: 100 * time.Millisecond, MaxDelay: time.Second})
+
+	var reported []int
+	retry.OnRetry = func(attempt int, wait time.Duration, err error) {
+		reported = append(reported, attempt)
+	}
+
+	explanation, err := retry.ExplainError(context.Background(), &domain.Error{})
+	if err != nil {
+		t.Fatalf("ExplainError returned error: %v", err)
+	}
+	if explanation != "explained" || flaky.calls != 3 || retry.Retries() != 2 {
+		t.Errorf("explanation = %q after %d calls and %d retries", explanation, flaky.calls, retry.Retries())
+	}
+	if len(*waits) != 2 || (*waits)[0] != 100*time.Millisecond || (*waits)[1] != 200*time.Millisecond {
+		t.Errorf("waits = %v, want exponential backoff 100ms then 200ms", *waits)
+	}
+	if len(reported) != 2 || reported[0] != 2 || reported[1] != 3 {
+		t.Errorf("OnRetry attempts = %v, want [2 3]", reported)
+	}
+}
+
+func TestRetryClientHonoursRetryAfter(t *testing.T) {
+	flaky := &flakyClient{errs: []error{
+		&APIError{Provider: "flaky", StatusCode: http.StatusTooManyRequests, RetryAfter: 7 * time.Second},
+	}}
+	retry, waits := newTestRetryClient(flaky, RetryPolicy{MaxAttempts: 3, BaseDelay: 100 * time.Millisecond, MaxDelay: 10 * time.Second})
+
+	if _, err := retry.TranslateQuery(context.Background(), "list files", ""); err != nil {
+		t.Fatalf("TranslateQuery returned error: %v", err)
+	}
+	if len(*waits) != 1 || (*waits)[0] != 7*time.Second {
+		t.Errorf("waits = %v, want the provider's 7s", *waits)
+	}
+}
+
+func TestRetryClientGivesUpOnLongRetryAfter(t *testing.T) {
+	limited := &APIError{Provider: "flaky", StatusCode: http.StatusTooManyRequests, RetryAfter: time.Hour}
+	flaky := &flakyClient{errs: []error{limited}}
+	retry, waits := newTestRetryClient(flaky, RetryPolicy{MaxAttempts: 3, BaseDelay: 100 * time.Millisecond, MaxDelay: 20 * time.Second})
+
+	_, err := retry.ExplainError(context.Background(), &domain.Error{})
+	if !errors.Is(err, limited) || flaky.calls != 1 || len(*waits) != 0 {
+		t.Errorf("err = %v after %d calls and waits %v, want the 429 at once rather than an hour's sleep", err, flaky.calls, *waits)
+	}
+}
+
+func TestRetryClientWaitsRetryAfterUpToMaxDelay(t *testing.T) {
+	flaky := &flakyClient{errs: []error{
+		&APIError{Provider: "flaky", StatusCode: http.StatusTooManyRequests, RetryAfter: 20 * time.Second},
+	}}
+	retry, waits := newTestRetryClient(flaky, RetryPolicy{MaxAttempts: 3, BaseDelay: 100 * time.Millisecond, MaxDelay: 20 * time.Second})
+
+	if _, err := retry.ExplainError(context.Background(), &domain.Error{}); err != nil {
+		t.Fatalf("ExplainError returned error: %v", err)
+	}
+	if len(*waits) != 1 || (*waits)[0] != 20*time.Second {
+		t.Errorf("waits = %v, want the provider's 20s, which is exactly MaxDelay", *waits)
+	}
+}
+
+func TestRetryClientStopsOnPermanentErrors(t *testing.T) {
+	badRequest := &APIError{Provider: "flaky", StatusCode: http.StatusBadRequest}
+	flaky := &flakyClient{errs: []error{badRequest}}
+	retry, _ := newTestRetryClient(flaky, DefaultRetryPolicy())
+
+	_, err := retry.ExplainError(context.Background(), &domain.Error{})
+	if !errors.Is(err, badRequest) || flaky.calls != 1 {
+		t.Errorf("err = %v after %d calls, want the 400 after one call", err, flaky.calls)
+	}
+}
+
+func TestRetryClientCapsAttempts(t *testing.T) {
+	unavailable := &APIError{Provider: "flaky", StatusCode: http.StatusServiceUnavailable}
+	flaky := &flakyClient{errs: []error{unavailable, unavailable, unavailable, unavailable}}
+	retry, _ := newTestRetryClient(flaky, RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond, MaxDelay: time.Millisecond})
+
+	_, err := retry.ExplainError(context.Background(), &domain.Error{})
+	if !errors.Is(err, unavailable) || flaky.calls != 3 {
+		t.Errorf("err = %v after %d calls, want the 503 after 3 calls", err, flaky.calls)
+	}
+}
+
+func TestRetryClientCapsElapsedTime(t *testing.T) {
+	flaky := &flakyClient{errs: []error{
+		&APIError{Provider: "flaky", StatusCode: http.StatusTooManyRequests, RetryAfter: time.Minute},
+	}}
+	retry, waits := newTestRetryClient(flaky, RetryPolicy{MaxAttempts: 3, MaxElapsed: 10 * time.Second})
+
+	if _, err := retry.ExplainError(context.Background(), &domain.Error{}); err == nil {
+		t.Fatal("a wait past MaxElapsed should give up")
+	}
+	if len(*waits) != 0 {
+		t.Errorf("waits = %v, want none", *waits)
+	}
+}
+
+func TestRetryClientStreamDoesNotRepeatText(t *testing.T) {
+	stream := &streamingFlakyClient{}
+	retry, _ := newTestRetryClient(stream, DefaultRetryPolicy())
+
+	var chunks []string
+	_, err := retry.ExplainErrorStream(context.Background(), &domain.Error{}, func(chunk string) {
+		chunks = append(chunks, chunk)
+	})
+	if err == nil || stream.calls != 1 {
+		t.Errorf("err = %v after %d calls, want the mid-stream failure after one call", err, stream.calls)
+	}
+	if len(chunks) != 1 {
+		t.Errorf("chunks = %q, want only the text sent before the failure", chunks)
+	}
+}
+
+// streamingFlakyClient sends one chunk and then fails with a retryable error
+type streamingFlakyClient struct {
+	flakyClient
+}
+
+func (c *streamingFlakyClient) ExplainErrorStream(ctx context.Context, err *domain.Error, onChunk func(string)) (string, error) {
+	c.calls++
+	onChunk("Partial ")
+	return "", &APIError{Provider: "flaky", StatusCode: http.StatusBadGateway}
+}
+
+func TestIsRetryableNetworkErrors(t *testing.T) {
+	// A server whose certificate nobody trusts, as with a wrong ai.http.ca_file
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	server.Config.ErrorLog = log.New(io.Discard, "", 0)
+	server.StartTLS()
+	defer server.Close()
+
+	local, err := NewLocalClient(&Config{BaseURL: server.URL}, LocalAPIOllama)
+	if err != nil {
+		t.Fatalf("NewLocalClient returned error: %v", err)
+	}
+	retry, waits := newTestRetryClient(local, DefaultRetryPolicy())
+	_, untrusted := retry.ExplainError(context.Background(), &domain.Error{Message: "undefined: fmt"})
+	if untrusted == nil || len(*waits) != 0 {
+		t.Errorf("err = %v after %d retries, want the certificate error at once", untrusted, len(*waits))
+	}
+
+	_, badScheme := http.Get("ftp://example.com/")
+
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"untrusted certificate", untrusted, false},
+		{"unsupported scheme", badScheme, false},
+		{"connection reset", fmt.Errorf("read: %w", syscall.ECONNRESET), true},
+		{"connection refused", fmt.Errorf("dial: %w", syscall.ECONNREFUSED), true},
+		{"cut-off answer", fmt.Errorf("reading body: %w", io.ErrUnexpectedEOF), true},
+		{"timeout", &TimeoutError{Provider: "local"}, true},
+	}
+	for _, tt := range tests {
+		if got := IsRetryable(tt.err); got != tt.want {
+			t.Errorf("%s: IsRetryable(%v) = %v, want %v", tt.name, tt.err, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,188 @@
+package ai
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/arnislvdev/go-guru-ui/internal/domain"
+)
+
+// maxTranslationRepairs is how many times a model is shown its broken answer and asked again
+const maxTranslationRepairs = 1
+
+// completion is one request to a provider's chat API
+type completion struct {
+	Task     string // TaskExplain, TaskTranslate or TaskChat; picks the model
+	System   string
+	Messages []chatMessage
+	JSON     bool         // ask for a JSON object, if the provider can enforce it
+	OnChunk  func(string) // stream text here as it arrives, if set
+}
+
+// completer is the single call every provider implements; the shared Client
+// behaviour (like translation with repair) is built on top of it
+type completer interface {
+	complete(ctx context.Context, req completion) (string, error)
+}
+
+// userPrompt returns a completion with one user message
+func userPrompt(system, prompt string) completion {
+	return completion{
+		System:   system,
+		Messages: []chatMessage{{Role: "user", Content: prompt}},
+	}
+}
+
+// translateQuery asks for a JSON command and checks it. If the answer doesn't
+// hold up, the model sees its answer and the problem and gets another try.
+func translateQuery(ctx context.Context, c completer, prompts *Prompts, query, contextInfo string) (*domain.Command, error) {
+	req, err := prompts.translateRequest(query, contextInfo)
+	if err != nil {
+		return nil, err
+	}
+	req.JSON = true
+
+	for attempt := 0; ; attempt++ {
+		response, err := c.complete(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+
+		command, parseErr := parseCommand(response, contextInfo)
+		if parseErr == nil {
+			return command, nil
+		}
+		if attempt >= maxTranslationRepairs {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidResponse, parseErr)
+		}
+
+		req.Messages = append(req.Messages,
+			chatMessage{Role: "assistant", Content: response},
+			chatMessage{Role: "user", Content: buildRepairPrompt(parseErr)},
+		)
+	}
+}
+
+var (
+	fencedBlockPattern = regexp.MustCompile("(?s)```([a-zA-Z]*)[ \t]*\n(.*?)```")
+	knownPlatforms     = map[string]string{
+		domain.PlatformLinux:   domain.PlatformLinux,
+		domain.PlatformMacOS:   domain.PlatformMacOS,
+		"mac":                  domain.PlatformMacOS,
+		"darwin":               domain.PlatformMacOS,
+		"osx":                  domain.PlatformMacOS,
+		domain.PlatformWindows: domain.PlatformWindows,
+		domain.PlatformUnknown: domain.PlatformUnknown,
+		"any":                  domain.PlatformUnknown,
+		"":                     domain.PlatformUnknown,
+	}
+)
+
+// parseCommand reads a translation answer. JSON is expected, bare or in a
+// fenced block; failing that, "Command:"/"Explanation:" lines or a fenced shell
+// block are accepted so models without a JSON mode still work.
+func parseCommand(response, contextInfo string) (*domain.Command, error) {
+	var command *domain.Command
+
+	if raw, ok := extractJSON(response); ok {
+		command = &domain.Command{}
+		if err := json.Unmarshal([]byte(raw), command); err != nil {
+			return nil, fmt.Errorf("the JSON does not match the schema: %v", err)
+		}
+	} else {
+		command = parseCommandText(response)
+	}
+
+	if err := validateCommand(command); err != nil {
+		return nil, err
+	}
+
+	// The platform named in the question beats a model's guess of "unknown"
+	command.Platform = knownPlatforms[strings.ToLower(strings.TrimSpace(command.Platform))]
+	if command.Platform == domain.PlatformUnknown {
+		command.Platform = detectPlatform(contextInfo)
+	}
+	command.Command = strings.TrimSpace(command.Command)
+	command.Explanation = strings.TrimSpace(command.Explanation)
+	command.Context = contextInfo
+
+	return command, nil
+}
+
+// validateCommand checks the fields we can't do without
+func validateCommand(command *domain.Command) error {
+	var problems []string
+	if strings.TrimSpace(command.Command) == "" {
+		problems = append(problems, `"command" is missing or empty`)
+	}
+	if strings.TrimSpace(command.Explanation) == "" {
+		problems = append(problems, `"explanation" is missing or empty`)
+	}
+	if _, ok := knownPlatforms[strings.ToLower(strings.TrimSpace(command.Platform))]; !ok {
+		problems = append(problems, fmt.Sprintf(`"platform" is %q but must be linux, macos, windows or unknown`, command.Platform))
+	}
+
+	if len(problems) > 0 {
+		return errors.New(strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// extractJSON finds the JSON object in a reply: the whole reply, a fenced block, or the outermost braces
+func extractJSON(response string) (string, bool) {
+	response = strings.TrimSpace(response)
+	if strings.HasPrefix(response, "{") && json.Valid([]byte(response)) {
+		return response, true
+	}
+
+	for _, match := range fencedBlockPattern.FindAllStringSubmatch(response, -1) {
+		body := strings.TrimSpace(match[2])
+		if strings.HasPrefix(body, "{") {
+			return body, true
+		}
+	}
+
+	// Prose around the object. Shell commands have braces too (find -exec {}),
+	// so only take this if it really is our object.
+	start := strings.Index(response, "{")
+	end := strings.LastIndex(response, "}")
+	if start >= 0 && end > start {
+		candidate := response[start : end+1]
+		if json.Valid([]byte(candidate)) && strings.Contains(candidate, `"command"`) {
+			return candidate, true
+		}
+	}
+	return "", false
+}
+
+// parseCommandText reads the older "Command: ... / Explanation: ..." format.
+// A fenced shell block stands in for the command when there is no Command: line,
+// and may span several lines.
+func parseCommandText(response string) *domain.Command {
+	command := &domain.Command{}
+
+	for _, line := range strings.Split(response, "\n") {
+		line = strings.TrimSpace(line)
+		if strings.HasPrefix(line, "Command:") {
+			command.Command = strings.Trim(strings.TrimSpace(strings.TrimPrefix(line, "Command:")), "`")
+		} else if strings.HasPrefix(line, "Explanation:") {
+			command.Explanation = strings.TrimSpace(strings.TrimPrefix(line, "Explanation:"))
+		}
+	}
+
+	if command.Command == "" {
+		if match := fencedBlockPattern.FindStringSubmatch(response); match != nil {
+			command.Command = strings.TrimSpace(match[2])
+		}
+	}
+	if command.Explanation == "" && command.Command != "" {
+		// Whatever prose surrounds the code block is the explanation
+		command.Explanation = strings.TrimSpace(fencedBlockPattern.ReplaceAllString(response, ""))
+	}
+
+	return command
+}
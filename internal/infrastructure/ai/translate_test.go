@@ -0,0 +1,136 @@
+package ai
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/arnislvdev/go-guru-ui/internal/domain"
+)
+
+func TestParseCommand(t *testing.T) {
+	tests := []struct {
+		name     string
+		response string
+		context  string
+		command  string
+		platform string
+	}{
+		{
+			name:     "bare JSON",
+			response: `{"command":"df -h","arguments":[],"flags":{"-h":"human-readable sizes"},"platform":"linux","explanation":"Shows disk space."}`,
+			command:  "df -h",
+			platform: domain.PlatformLinux,
+		},
+		{
+			name:     "fenced JSON with prose",
+			response: "Sure!\n```json\n{\"command\":\"du -sh .\",\"platform\":\"macos\",\"explanation\":\"Folder size.\"}\n```\nHope that helps.",
+			command:  "du -sh .",
+			platform: domain.PlatformMacOS,
+		},
+		{
+			name:     "unknown platform takes the context's",
+			response: `{"command":"ls","platform":"unknown","explanation":"Lists files."}`,
+			context:  "I'm on Ubuntu",
+			command:  "ls",
+			platform: domain.PlatformLinux,
+		},
+		{
+			name:     "Command and Explanation lines",
+			response: "Command: find . -name '*.tmp' -exec rm {} +\nExplanation: Deletes temp files.",
+			command:  "find . -name '*.tmp' -exec rm {} +",
+			platform: domain.PlatformUnknown,
+		},
+		{
+			name:     "multi-line fenced shell block",
+			response: "Run this:\n```bash\ncd /tmp &&\n  ls -la\n```\nIt lists /tmp.",
+			command:  "cd /tmp &&\n  ls -la",
+			platform: domain.PlatformUnknown,
+		},
+	}
+
+	for _, test := range tests {
+		command, err := parseCommand(test.response, test.context)
+		if err != nil {
+			t.Errorf("%s: parseCommand returned error: %v", test.name, err)
+			continue
+		}
+		if command.Command != test.command || command.Platform != test.platform || command.Explanation == "" {
+			t.Errorf("%s: got %+v", test.name, command)
+		}
+	}
+}
+
+func TestParseCommandFillsFlagsAndArguments(t *testing.T) {
+	command, err := parseCommand(`{"command":"tar -xzf app.tgz","arguments":["app.tgz"],"flags":{"-x":"extract","-z":"gunzip","-f":"archive file"},"platform":"linux","explanation":"Unpacks app.tgz."}`, "")
+	if err != nil {
+		t.Fatalf("parseCommand returned error: %v", err)
+	}
+	if len(command.Arguments) != 1 || command.Arguments[0] != "app.tgz" || command.Flags["-z"] != "gunzip" {
+		t.Errorf("got %+v", command)
+	}
+}
+
+func TestParseCommandRejectsBadAnswers(t *testing.T) {
+	bad := []string{
+		`{"command":"","explanation":"nothing"}`,
+		`{"command":"ls","platform":"beos","explanation":"Lists files."}`,
+		`{"command":"ls","flags":["-l"],"explanation":"Lists files."}`,
+		"I'm not sure what you mean.",
+	}
+
+	for _, response := range bad {
+		if command, err := parseCommand(response, ""); err == nil {
+			t.Errorf("parseCommand(%q) = %+v, want an error", response, command)
+		}
+	}
+}
+
+// scriptedCompleter replies with the queued responses and records each request
+type scriptedCompleter struct {
+	responses []string
+	requests  []completion
+}
+
+func (s *scriptedCompleter) complete(ctx context.Context, req completion) (string, error) {
+	s.requests = append(s.requests, req)
+	response := s.responses[0]
+	s.responses = s.responses[1:]
+	return response, nil
+}
+
+func TestTranslateQueryRepairsInvalidJSON(t *testing.T) {
+	completer := &scriptedCompleter{responses: []string{
+		`{"command": "df -h", "explanation": }`,
+		`{"command":"df -h","platform":"linux","explanation":"Shows disk space."}`,
+	}}
+
+	command, err := translateQuery(context.Background(), completer, DefaultPrompts(), "check disk space", "")
+	if err != nil {
+		t.Fatalf("translateQuery returned error: %v", err)
+	}
+	if command.Command != "df -h" {
+		t.Errorf("command = %+v", command)
+	}
+
+	if len(completer.requests) != 2 {
+		t.Fatalf("requests = %d, want the original and one repair", len(completer.requests))
+	}
+	repair := completer.requests[1]
+	if !repair.JSON || len(repair.Messages) != 3 || repair.Messages[1].Role != "assistant" {
+		t.Errorf("repair request = %+v, want the bad answer and a fix-it message", repair)
+	}
+	if !strings.Contains(repair.Messages[2].Content, "could not be used") {
+		t.Errorf("repair message = %q", repair.Messages[2].Content)
+	}
+}
+
+func TestTranslateQueryGivesUpAfterRepair(t *testing.T) {
+	completer := &scriptedCompleter{responses: []string{"no idea", "still no idea"}}
+
+	_, err := translateQuery(context.Background(), completer, DefaultPrompts(), "do the thing", "")
+	if !errors.Is(err, ErrInvalidResponse) {
+		t.Errorf("err = %v, want ErrInvalidResponse", err)
+	}
+}
@@ -0,0 +1,291 @@
+package ai
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// headerTransport adds fixed headers to every request before handing it to base
+type headerTransport struct {
+	headers map[string]string
+	base    http.RoundTripper
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *headerTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	// RoundTrippers must not change the caller's request
+	req = req.Clone(req.Context())
+	for name, value := range t.headers {
+		req.Header.Set(name, value)
+	}
+	return t.base.RoundTrip(req)
+}
+
+// HTTPConfig controls how requests reach a provider: through which proxy,
+// trusting which certificates, and how long to wait. The zero value uses
+// HTTPS_PROXY/NO_PROXY from the environment and the system certificates.
+type HTTPConfig struct {
+	Proxy   string   `json:"proxy,omitempty"`    // e.g. http://proxy.corp:8080; empty uses HTTPS_PROXY/HTTP_PROXY
+	NoProxy []string `json:"no_proxy,omitempty"` // hosts, .domains, IPs or CIDRs reached directly; "*" skips the proxy
+
+	CAFile   string `json:"ca_file,omitempty"`   // PEM bundle trusted on top of the system certificates
+	CertFile string `json:"cert_file,omitempty"` // PEM client certificate for mutual TLS
+	KeyFile  string `json:"key_file,omitempty"`  // its private key; empty means it is in CertFile
+
+	ConnectTimeout time.Duration `json:"connect_timeout,omitempty"` // connecting and the TLS handshake; zero uses Go's defaults
+	ReadTimeout    time.Duration `json:"read_timeout,omitempty"`    // waiting for the provider to start answering; zero means no limit
+}
+
+// IsZero reports whether no HTTP settings are set
+func (h HTTPConfig) IsZero() bool {
+	return h.Proxy == "" && len(h.NoProxy) == 0 && h.CAFile == "" && h.CertFile == "" &&
+		h.KeyFile == "" && h.ConnectTimeout == 0 && h.ReadTimeout == 0
+}
+
+// Describe sums up the settings in one line, for --verbose. Proxy passwords are hidden.
+func (h HTTPConfig) Describe() string {
+	var parts []string
+
+	switch {
+	case h.Proxy != "":
+		proxy := h.Proxy
+		if u, err := parseProxyURL(h.Proxy); err == nil {
+			proxy = u.Redacted()
+		}
+		parts = append(parts, "proxy "+proxy)
+	case os.Getenv("HTTPS_PROXY") != "" || os.Getenv("https_proxy") != "" ||
+		os.Getenv("HTTP_PROXY") != "" || os.Getenv("http_proxy") != "":
+		parts = append(parts, "proxy from environment")
+	default:
+		parts = append(parts, "no proxy")
+	}
+	if len(h.NoProxy) > 0 {
+		parts = append(parts, "direct to "+strings.Join(h.NoProxy, ", "))
+	}
+
+	if h.CAFile != "" {
+		parts = append(parts, "CA bundle "+h.CAFile)
+	} else {
+		parts = append(parts, "system CAs")
+	}
+	if h.CertFile != "" {
+		parts = append(parts, "client certificate "+h.CertFile)
+	}
+
+	if h.ConnectTimeout > 0 {
+		parts = append(parts, "connect timeout "+h.ConnectTimeout.String())
+	}
+	if h.ReadTimeout > 0 {
+		parts = append(parts, "read timeout "+h.ReadTimeout.String())
+	}
+	return strings.Join(parts, ", ")
+}
+
+// newHTTPClient returns an HTTP client that follows the config's HTTP
+// settings and sends its extra headers
+func newHTTPClient(cfg *Config) (*http.Client, error) {
+	var base http.RoundTripper = http.DefaultTransport
+	if !cfg.HTTP.IsZero() {
+		transport, err := newTransport(cfg.HTTP)
+		if err != nil {
+			return nil, err
+		}
+		base = transport
+	}
+
+	if len(cfg.Headers) == 0 {
+		if base == http.DefaultTransport {
+			return &http.Client{}, nil
+		}
+		return &http.Client{Transport: base}, nil
+	}
+	return &http.Client{
+		Transport: &headerTransport{headers: cfg.Headers, base: base},
+	}, nil
+}
+
+// newTransport builds a transport for h on top of Go's default one
+func newTransport(h HTTPConfig) (*http.Transport, error) {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+
+	proxy, err := proxyFunc(h)
+	if err != nil {
+		return nil, err
+	}
+	transport.Proxy = proxy
+
+	tlsConfig, err := tlsConfig(h)
+	if err != nil {
+		return nil, err
+	}
+	if tlsConfig != nil {
+		transport.TLSClientConfig = tlsConfig
+	}
+
+	if h.ConnectTimeout > 0 {
+		dialer := &net.Dialer{Timeout: h.ConnectTimeout, KeepAlive: 30 * time.Second}
+		transport.DialContext = dialer.DialContext
+		transport.TLSHandshakeTimeout = h.ConnectTimeout
+	}
+	transport.ResponseHeaderTimeout = h.ReadTimeout
+
+	return transport, nil
+}
+
+// proxyFunc picks the proxy for each request: h.Proxy if set, otherwise the
+// environment's, and none for hosts in h.NoProxy
+func proxyFunc(h HTTPConfig) (func(*http.Request) (*url.URL, error), error) {
+	proxy := http.ProxyFromEnvironment
+	if h.Proxy != "" {
+		u, err := parseProxyURL(h.Proxy)
+		if err != nil {
+			return nil, err
+		}
+		proxy = http.ProxyURL(u)
+	}
+	if len(h.NoProxy) == 0 {
+		return proxy, nil
+	}
+
+	return func(req *http.Request) (*url.URL, error) {
+		if bypassProxy(req.URL.Hostname(), h.NoProxy) {
+			return nil, nil
+		}
+		return proxy(req)
+	}, nil
+}
+
+// parseProxyURL reads a proxy address; a bare host:port means an HTTP proxy
+func parseProxyURL(raw string) (*url.URL, error) {
+	if !strings.Contains(raw, "://") {
+		raw = "http://" + raw
+	}
+	u, err := url.Parse(raw)
+	if err != nil {
+		return nil, fmt.Errorf("invalid proxy %q: %w", raw, err)
+	}
+	switch u.Scheme {
+	case "http", "https", "socks5", "socks5h":
+	default:
+		return nil, fmt.Errorf("invalid proxy %q: scheme must be http, https or socks5", raw)
+	}
+	if u.Host == "" {
+		return nil, fmt.Errorf("invalid proxy %q: no host", raw)
+	}
+	return u, nil
+}
+
+// bypassProxy reports whether host matches a no-proxy entry. Entries work like
+// NO_PROXY: "*" matches everything, "corp.com" and ".corp.com" match the domain
+// and its subdomains, and IPs or CIDRs match addresses. Ports are ignored.
+func bypassProxy(host string, noProxy []string) bool {
+	host = strings.ToLower(strings.TrimSuffix(host, "."))
+	ip := net.ParseIP(host)
+
+	for _, entry := range noProxy {
+		entry = strings.ToLower(strings.TrimSpace(entry))
+		if entry == "" {
+			continue
+		}
+		if entry == "*" {
+			return true
+		}
+
+		if _, network, err := net.ParseCIDR(entry); err == nil {
+			if ip != nil && network.Contains(ip) {
+				return true
+			}
+			continue
+		}
+		if h, _, err := net.SplitHostPort(entry); err == nil {
+			entry = h
+		}
+		entry = strings.Trim(entry, "[]")
+		if entryIP := net.ParseIP(entry); entryIP != nil {
+			if ip != nil && entryIP.Equal(ip) {
+				return true
+			}
+			continue
+		}
+
+		domain := strings.TrimPrefix(strings.TrimPrefix(entry, "*"), ".")
+		if host == domain || strings.HasSuffix(host, "."+domain) {
+			return true
+		}
+	}
+	return false
+}
+
+// tlsConfig loads the CA bundle and client certificate, or returns nil when
+// neither is set
+func tlsConfig(h HTTPConfig) (*tls.Config, error) {
+	if h.CAFile == "" && h.CertFile == "" && h.KeyFile == "" {
+		return nil, nil
+	}
+	config := &tls.Config{MinVersion: tls.VersionTLS12}
+
+	if h.CAFile != "" {
+		pem, err := os.ReadFile(h.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CA bundle: %w", err)
+		}
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no PEM certificates found in CA bundle %s", h.CAFile)
+		}
+		config.RootCAs = pool
+	}
+
+	if h.KeyFile != "" && h.CertFile == "" {
+		return nil, fmt.Errorf("client key %s is set without a client certificate", h.KeyFile)
+	}
+	if h.CertFile != "" {
+		keyFile := h.KeyFile
+		if keyFile == "" {
+			keyFile = h.CertFile
+		}
+		cert, err := tls.LoadX509KeyPair(h.CertFile, keyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load client certificate %s: %w", h.CertFile, err)
+		}
+		config.Certificates = []tls.Certificate{cert}
+	}
+
+	return config, nil
+}
+
+// retryAfterTransport remembers the Retry-After header of the most recent response
+type retryAfterTransport struct {
+	base  http.RoundTripper
+	value atomic.Int64
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *retryAfterTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	base := t.base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+
+	resp, err := base.RoundTrip(req)
+	if err == nil {
+		t.value.Store(int64(parseRetryAfter(resp.Header.Get("Retry-After"))))
+	}
+	return resp, err
+}
+
+// last returns the wait the most recent response asked for
+func (t *retryAfterTransport) last() time.Duration {
+	return time.Duration(t.value.Load())
+}
@@ -0,0 +1,80 @@
+package ai
+
+import (
+	"context"
+	"sync"
+)
+
+// Usage is the tokens one request to a provider used
+type Usage struct {
+	Provider     string `json:"provider"`
+	Model        string `json:"model"`
+	InputTokens  int    `json:"input_tokens"`
+	OutputTokens int    `json:"output_tokens"`
+
+	// Estimated is set when the provider didn't say and the counts were guessed from the text
+	Estimated bool `json:"estimated,omitempty"`
+}
+
+// TotalTokens returns input and output tokens together
+func (u Usage) TotalTokens() int {
+	return u.InputTokens + u.OutputTokens
+}
+
+// UsageMeter collects the usage of every request made with its context. Any
+// Client reports to it, including each retry and each provider in a fallback
+// chain, so the total is what was really spent. Answers from the cache or the
+// offline explainer cost nothing and report nothing.
+type UsageMeter struct {
+	mu      sync.Mutex
+	records []Usage
+}
+
+type usageMeterKey struct{}
+
+// WithUsageMeter returns a context whose requests report their usage to meter
+func WithUsageMeter(ctx context.Context, meter *UsageMeter) context.Context {
+	return context.WithValue(ctx, usageMeterKey{}, meter)
+}
+
+// Record adds the usage of one request
+func (m *UsageMeter) Record(u Usage) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.records = append(m.records, u)
+}
+
+// Records returns the usage of each request so far, in order
+func (m *UsageMeter) Records() []Usage {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]Usage(nil), m.records...)
+}
+
+// reportUsage records u with the context's meter, if there is one
+func reportUsage(ctx context.Context, u Usage) {
+	if meter, ok := ctx.Value(usageMeterKey{}).(*UsageMeter); ok && meter != nil {
+		meter.Record(u)
+	}
+}
+
+// estimateTokens guesses a token count from text, at about four characters a token
+func estimateTokens(text string) int {
+	return (len(text) + 3) / 4
+}
+
+// estimateUsage guesses the usage of a request for providers that don't report it
+func estimateUsage(provider, model string, req completion, reply string) Usage {
+	input := estimateTokens(req.System)
+	for _, message := range req.Messages {
+		input += estimateTokens(message.Content)
+	}
+
+	return Usage{
+		Provider:     provider,
+		Model:        model,
+		InputTokens:  input,
+		OutputTokens: estimateTokens(reply),
+		Estimated:    true,
+	}
+}
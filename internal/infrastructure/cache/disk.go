@@ -0,0 +1,234 @@
+package cache
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// entrySuffix marks cache entry files, so Clear never touches anything else
+const entrySuffix = ".json"
+
+// DiskCache stores values as one file per key. A file's modification time is
+// its last use, which drives least-recently-used eviction.
+type DiskCache struct {
+	dir      string
+	ttl      time.Duration
+	maxBytes int64
+	now      func() time.Time
+}
+
+// Stats describes what is in the cache
+type Stats struct {
+	Dir      string
+	Entries  int
+	Bytes    int64
+	Expired  int
+	MaxBytes int64
+	TTL      time.Duration
+}
+
+// entry is what one cache file holds
+type entry struct {
+	Created time.Time       `json:"created"`
+	Value   json.RawMessage `json:"value"`
+}
+
+// DefaultDir returns where cached answers live: the user cache dir plus guruui/responses
+func DefaultDir() (string, error) {
+	base, err := os.UserCacheDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to find cache directory: %w", err)
+	}
+	return filepath.Join(base, "guruui", "responses"), nil
+}
+
+// NewDiskCache opens (and creates if needed) a cache in dir. Entries older than
+// ttl are ignored; once the cache grows past maxBytes the least recently used
+// entries are removed. Zero ttl or maxBytes means no limit.
+func NewDiskCache(dir string, ttl time.Duration, maxBytes int64) (*DiskCache, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, fmt.Errorf("failed to create cache directory: %w", err)
+	}
+
+	return &DiskCache{
+		dir:      dir,
+		ttl:      ttl,
+		maxBytes: maxBytes,
+		now:      time.Now,
+	}, nil
+}
+
+// Get returns the value stored under key, if it is there and not expired
+func (c *DiskCache) Get(key string) ([]byte, bool) {
+	path := c.path(key)
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, false
+	}
+
+	var e entry
+	if err := json.Unmarshal(data, &e); err != nil {
+		os.Remove(path)
+		return nil, false
+	}
+	if c.expired(e.Created) {
+		os.Remove(path)
+		return nil, false
+	}
+
+	// Mark as recently used
+	now := c.now()
+	os.Chtimes(path, now, now)
+
+	return e.Value, true
+}
+
+// Put stores a JSON value under key, then trims the cache to its size limit
+func (c *DiskCache) Put(key string, value []byte) error {
+	data, err := json.Marshal(entry{Created: c.now(), Value: value})
+	if err != nil {
+		return fmt.Errorf("failed to encode cache entry: %w", err)
+	}
+
+	// Write then rename, so a reader never sees half an entry
+	tmp, err := os.CreateTemp(c.dir, "tmp-*")
+	if err != nil {
+		return fmt.Errorf("failed to write cache entry: %w", err)
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return fmt.Errorf("failed to write cache entry: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("failed to write cache entry: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), c.path(key)); err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("failed to write cache entry: %w", err)
+	}
+
+	return c.evict()
+}
+
+// Clear removes every entry and returns how many there were
+func (c *DiskCache) Clear() (int, error) {
+	files, err := c.files()
+	if err != nil {
+		return 0, err
+	}
+
+	removed := 0
+	for _, f := range files {
+		if err := os.Remove(f.path); err != nil && !os.IsNotExist(err) {
+			return removed, fmt.Errorf("failed to remove cache entry: %w", err)
+		}
+		removed++
+	}
+	return removed, nil
+}
+
+// Stats reports the cache's size and how many entries have expired
+func (c *DiskCache) Stats() (Stats, error) {
+	stats := Stats{Dir: c.dir, MaxBytes: c.maxBytes, TTL: c.ttl}
+
+	files, err := c.files()
+	if err != nil {
+		return stats, err
+	}
+
+	for _, f := range files {
+		stats.Entries++
+		stats.Bytes += f.size
+		if data, err := os.ReadFile(f.path); err == nil {
+			var e entry
+			if json.Unmarshal(data, &e) != nil || c.expired(e.Created) {
+				stats.Expired++
+			}
+		}
+	}
+	return stats, nil
+}
+
+// cacheFile is one entry on disk
+type cacheFile struct {
+	path    string
+	size    int64
+	lastUse time.Time
+}
+
+// files lists the cache entries on disk
+func (c *DiskCache) files() ([]cacheFile, error) {
+	dirEntries, err := os.ReadDir(c.dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read cache directory: %w", err)
+	}
+
+	var files []cacheFile
+	for _, d := range dirEntries {
+		if d.IsDir() || !strings.HasSuffix(d.Name(), entrySuffix) {
+			continue
+		}
+		info, err := d.Info()
+		if err != nil {
+			continue
+		}
+		files = append(files, cacheFile{
+			path:    filepath.Join(c.dir, d.Name()),
+			size:    info.Size(),
+			lastUse: info.ModTime(),
+		})
+	}
+	return files, nil
+}
+
+// evict removes least recently used entries until the cache fits in maxBytes
+func (c *DiskCache) evict() error {
+	if c.maxBytes <= 0 {
+		return nil
+	}
+
+	files, err := c.files()
+	if err != nil {
+		return err
+	}
+
+	var total int64
+	for _, f := range files {
+		total += f.size
+	}
+	if total <= c.maxBytes {
+		return nil
+	}
+
+	sort.Slice(files, func(i, j int) bool {
+		return files[i].lastUse.Before(files[j].lastUse)
+	})
+	for _, f := range files {
+		if total <= c.maxBytes {
+			break
+		}
+		if err := os.Remove(f.path); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to evict cache entry: %w", err)
+		}
+		total -= f.size
+	}
+	return nil
+}
+
+// expired reports whether an entry created at created is past its TTL
+func (c *DiskCache) expired(created time.Time) bool {
+	return c.ttl > 0 && c.now().Sub(created) > c.ttl
+}
+
+// path returns the file for key. Keys are expected to be hashes and safe as file names.
+func (c *DiskCache) path(key string) string {
+	return filepath.Join(c.dir, key+entrySuffix)
+}
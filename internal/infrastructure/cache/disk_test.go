@@ -0,0 +1,90 @@
+package cache
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestDiskCacheGetPut(t *testing.T) {
+	c, err := NewDiskCache(t.TempDir(), time.Hour, 0)
+	if err != nil {
+		t.Fatalf("NewDiskCache returned error: %v", err)
+	}
+
+	if _, ok := c.Get("missing"); ok {
+		t.Error("Get on an empty cache should miss")
+	}
+	if err := c.Put("abc", []byte(`"hello"`)); err != nil {
+		t.Fatalf("Put returned error: %v", err)
+	}
+
+	value, ok := c.Get("abc")
+	if !ok || string(value) != `"hello"` {
+		t.Errorf("Get = %s, %v; want \"hello\"", value, ok)
+	}
+}
+
+func TestDiskCacheTTL(t *testing.T) {
+	c, _ := NewDiskCache(t.TempDir(), time.Hour, 0)
+	start := time.Now()
+	c.now = func() time.Time { return start }
+	c.Put("abc", []byte(`1`))
+
+	c.now = func() time.Time { return start.Add(2 * time.Hour) }
+	stats, _ := c.Stats()
+	if stats.Entries != 1 || stats.Expired != 1 {
+		t.Errorf("stats = %+v, want one expired entry", stats)
+	}
+	if _, ok := c.Get("abc"); ok {
+		t.Error("an entry past its TTL should miss")
+	}
+}
+
+func TestDiskCacheEvictsLeastRecentlyUsed(t *testing.T) {
+	dir := t.TempDir()
+	c, _ := NewDiskCache(dir, 0, 0)
+
+	value := []byte(`"0123456789012345678901234567890123456789"`)
+	base := time.Now().Add(-time.Hour)
+	for i, key := range []string{"old", "used", "new"} {
+		c.Put(key, value)
+		at := base.Add(time.Duration(i) * time.Minute)
+		os.Chtimes(filepath.Join(dir, key+entrySuffix), at, at)
+	}
+
+	// Touching "used" makes "old" the least recently used
+	c.Get("used")
+
+	stats, _ := c.Stats()
+	c.maxBytes = stats.Bytes - 1
+	if err := c.evict(); err != nil {
+		t.Fatalf("evict returned error: %v", err)
+	}
+
+	if _, ok := c.Get("old"); ok {
+		t.Error("the least recently used entry should be evicted")
+	}
+	for _, key := range []string{"used", "new"} {
+		if _, ok := c.Get(key); !ok {
+			t.Errorf("%s should still be cached", key)
+		}
+	}
+}
+
+func TestDiskCacheClear(t *testing.T) {
+	dir := t.TempDir()
+	c, _ := NewDiskCache(dir, 0, 0)
+	c.Put("a", []byte(`1`))
+	c.Put("b", []byte(`2`))
+	os.WriteFile(filepath.Join(dir, "notes.txt"), []byte("keep me"), 0o600)
+
+	removed, err := c.Clear()
+	if err != nil || removed != 2 {
+		t.Errorf("Clear = %d, %v; want 2 entries removed", removed, err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "notes.txt")); err != nil {
+		t.Error("Clear should only remove cache entries")
+	}
+}
@@ -0,0 +1,175 @@
+package chat
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/arnislvdev/go-guru-ui/internal/domain"
+)
+
+// ErrNotFound means no saved conversation has the given ID
+var ErrNotFound = errors.New("no such conversation")
+
+// Store keeps conversations as JSON files, one per ID, in a directory
+type Store struct {
+	dir string
+}
+
+// DefaultDir returns where conversations are kept: the user config dir plus guruui/chats
+func DefaultDir() (string, error) {
+	base, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to find config directory: %w", err)
+	}
+	return filepath.Join(base, "guruui", "chats"), nil
+}
+
+// NewStore returns a store kept in dir. The directory is created on first save.
+func NewStore(dir string) *Store {
+	return &Store{dir: dir}
+}
+
+// Save writes conv, giving it an ID and creation time the first time
+func (s *Store) Save(conv *domain.Conversation) error {
+	now := time.Now()
+	if conv.ID == "" {
+		id, err := newID()
+		if err != nil {
+			return err
+		}
+		conv.ID = id
+		conv.Created = now
+	}
+	conv.Updated = now
+
+	if err := os.MkdirAll(s.dir, 0o700); err != nil {
+		return fmt.Errorf("failed to create chat directory: %w", err)
+	}
+
+	data, err := json.MarshalIndent(conv, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode conversation: %w", err)
+	}
+
+	path := s.path(conv.ID)
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return fmt.Errorf("failed to write conversation: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("failed to write conversation: %w", err)
+	}
+	return nil
+}
+
+// Load reads the conversation with the given ID. Like git, the start of an
+// ID is enough when only one conversation begins with it.
+func (s *Store) Load(id string) (*domain.Conversation, error) {
+	id = strings.TrimSpace(id)
+	if id == "" || strings.ContainsAny(id, `/\.`) {
+		return nil, fmt.Errorf("%w: %q", ErrNotFound, id)
+	}
+
+	ids, err := s.ids()
+	if err != nil {
+		return nil, err
+	}
+	var matches []string
+	for _, known := range ids {
+		if known == id {
+			matches = []string{known}
+			break
+		}
+		if strings.HasPrefix(known, id) {
+			matches = append(matches, known)
+		}
+	}
+	switch len(matches) {
+	case 0:
+		return nil, fmt.Errorf("%w: %q", ErrNotFound, id)
+	case 1:
+		return s.read(matches[0])
+	default:
+		return nil, fmt.Errorf("conversation ID %q is ambiguous: matches %s", id, strings.Join(matches, ", "))
+	}
+}
+
+// List returns every saved conversation, most recently updated first.
+// Files that can't be read are skipped.
+func (s *Store) List() ([]*domain.Conversation, error) {
+	ids, err := s.ids()
+	if err != nil {
+		return nil, err
+	}
+
+	var convs []*domain.Conversation
+	for _, id := range ids {
+		if conv, err := s.read(id); err == nil {
+			convs = append(convs, conv)
+		}
+	}
+	sort.Slice(convs, func(i, j int) bool {
+		return convs[i].Updated.After(convs[j].Updated)
+	})
+	return convs, nil
+}
+
+// ids returns the IDs of the saved conversations
+func (s *Store) ids() ([]string, error) {
+	entries, err := os.ReadDir(s.dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read chat directory: %w", err)
+	}
+
+	var ids []string
+	for _, entry := range entries {
+		if name := entry.Name(); !entry.IsDir() && strings.HasSuffix(name, ".json") {
+			ids = append(ids, strings.TrimSuffix(name, ".json"))
+		}
+	}
+	return ids, nil
+}
+
+// read loads one conversation file. A file without the error the
+// conversation is about, hand-edited or cut short, is refused.
+func (s *Store) read(id string) (*domain.Conversation, error) {
+	data, err := os.ReadFile(s.path(id))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read conversation: %w", err)
+	}
+
+	var conv domain.Conversation
+	if err := json.Unmarshal(data, &conv); err != nil {
+		return nil, fmt.Errorf("failed to parse conversation %s: %w", id, err)
+	}
+	if conv.Error == nil {
+		return nil, fmt.Errorf("conversation %s is missing the error it is about", id)
+	}
+	return &conv, nil
+}
+
+// path returns the file for id
+func (s *Store) path(id string) string {
+	return filepath.Join(s.dir, id+".json")
+}
+
+// newID returns a short random ID
+func newID() (string, error) {
+	b := make([]byte, 4)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to make conversation ID: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
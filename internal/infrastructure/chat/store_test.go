@@ -0,0 +1,84 @@
+package chat
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/arnislvdev/go-guru-ui/internal/domain"
+)
+
+func TestStoreSaveAndLoad(t *testing.T) {
+	store := NewStore(filepath.Join(t.TempDir(), "chats"))
+
+	conv := &domain.Conversation{
+		Error:    &domain.Error{Message: "undefined: fmt"},
+		Messages: []domain.Message{{Role: domain.RoleAssistant, Content: "Import fmt."}},
+	}
+	if err := store.Save(conv); err != nil {
+		t.Fatalf("Save returned error: %v", err)
+	}
+	if conv.ID == "" || conv.Created.IsZero() {
+		t.Fatalf("Save should give the conversation an ID and creation time, got %+v", conv)
+	}
+
+	conv.Messages = append(conv.Messages, domain.Message{Role: domain.RoleUser, Content: "Why?"})
+	if err := store.Save(conv); err != nil {
+		t.Fatalf("second Save returned error: %v", err)
+	}
+
+	loaded, err := store.Load(conv.ID[:4])
+	if err != nil {
+		t.Fatalf("Load by prefix returned error: %v", err)
+	}
+	if loaded.ID != conv.ID || len(loaded.Messages) != 2 || loaded.Error.Message != "undefined: fmt" {
+		t.Errorf("loaded = %+v", loaded)
+	}
+
+	if _, err := store.Load("nothere"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Load of unknown ID = %v, want ErrNotFound", err)
+	}
+	if _, err := store.Load("../secret"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Load of a path = %v, want ErrNotFound", err)
+	}
+}
+
+func TestStoreListNewestFirst(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "chats")
+	store := NewStore(dir)
+
+	convs, err := store.List()
+	if err != nil || len(convs) != 0 {
+		t.Fatalf("List of a missing directory = %v, %v; want nothing", convs, err)
+	}
+
+	first := &domain.Conversation{Error: &domain.Error{Message: "first"}}
+	second := &domain.Conversation{Error: &domain.Error{Message: "second"}}
+	for _, conv := range []*domain.Conversation{first, second} {
+		if err := store.Save(conv); err != nil {
+			t.Fatalf("Save returned error: %v", err)
+		}
+	}
+	// Touch the first again so it is the most recent
+	if err := store.Save(first); err != nil {
+		t.Fatalf("Save returned error: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "broken.json"), []byte("{"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "noerror.json"), []byte(`{"id":"noerror","messages":[]}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	convs, err = store.List()
+	if err != nil {
+		t.Fatalf("List returned error: %v", err)
+	}
+	if len(convs) != 2 || convs[0].ID != first.ID || convs[1].ID != second.ID {
+		t.Errorf("List = %+v, want first then second, skipping the broken files", convs)
+	}
+	if _, err := store.Load("noerror"); err == nil {
+		t.Error("Load of a conversation without its error succeeded, want an error")
+	}
+}
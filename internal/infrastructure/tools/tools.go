@@ -0,0 +1,381 @@
+package tools
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Limits on what one tool call may read or print
+const (
+	maxOutput    = 8000    // bytes of tool output passed back to the AI
+	maxReadLines = 200     // lines read_file returns at once
+	maxGrepHits  = 50      // matches grep returns
+	maxGrepFile  = 1 << 20 // files larger than this are skipped by grep
+	cmdTimeout   = 10 * time.Second
+)
+
+// ErrOutsideSandbox means a tool was asked for a path outside the sandbox root
+var ErrOutsideSandbox = errors.New("path is outside the project")
+
+// Tool is something the AI may run to look at the user's code. Every tool only reads.
+type Tool interface {
+	// Name is what the AI calls the tool by
+	Name() string
+
+	// Usage describes the arguments and the result, for the AI
+	Usage() string
+
+	// Run runs the tool and returns what it found
+	Run(ctx context.Context, args map[string]string) (string, error)
+}
+
+// tool is a Tool made from a function
+type tool struct {
+	name  string
+	usage string
+	run   func(ctx context.Context, args map[string]string) (string, error)
+}
+
+func (t *tool) Name() string  { return t.name }
+func (t *tool) Usage() string { return t.usage }
+
+func (t *tool) Run(ctx context.Context, args map[string]string) (string, error) {
+	out, err := t.run(ctx, args)
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", t.name, err)
+	}
+	return truncate(out), nil
+}
+
+// Sandbox runs tools against one project directory. Paths outside it,
+// including through symlinks, are refused.
+type Sandbox struct {
+	root string
+}
+
+// NewSandbox returns a sandbox rooted at dir
+func NewSandbox(dir string) (*Sandbox, error) {
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find project directory: %w", err)
+	}
+	root, err := filepath.EvalSymlinks(abs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find project directory: %w", err)
+	}
+	return &Sandbox{root: root}, nil
+}
+
+// Tools returns every tool, run in the sandbox
+func (s *Sandbox) Tools() []Tool {
+	return []Tool{
+		&tool{"read_file", `{"path": "main.go", "start": "10", "end": "40"}: numbered lines of a file (at most 200 at once)`, s.readFile},
+		&tool{"go_doc", `{"symbol": "fmt.Println"}: the output of go doc for a package or symbol`, s.goDoc},
+		&tool{"list_symbols", `{"dir": "internal/app"}: the top-level functions, types, variables and constants of a Go package, with their lines`, s.listSymbols},
+		&tool{"grep", `{"pattern": "func Load", "path": "internal"}: lines matching a regular expression, with file and line (path is optional)`, s.grep},
+		&tool{"go_env", `{"names": "GOOS GOPATH"}: the output of go env, for the given variables or all of them`, s.goEnv},
+	}
+}
+
+// Select returns the tools whose names are not in disabled
+func Select(all []Tool, disabled []string) []Tool {
+	off := make(map[string]bool)
+	for _, name := range disabled {
+		off[strings.TrimSpace(name)] = true
+	}
+
+	var selected []Tool
+	for _, t := range all {
+		if !off[t.Name()] {
+			selected = append(selected, t)
+		}
+	}
+	return selected
+}
+
+// readFile returns a range of numbered lines
+func (s *Sandbox) readFile(ctx context.Context, args map[string]string) (string, error) {
+	path, err := s.resolve(args["path"])
+	if err != nil {
+		return "", err
+	}
+	start, err := lineArg(args, "start", 1)
+	if err != nil {
+		return "", err
+	}
+	end, err := lineArg(args, "end", start+maxReadLines-1)
+	if err != nil {
+		return "", err
+	}
+	if end < start {
+		return "", fmt.Errorf("end %d is before start %d", end, start)
+	}
+	if end-start >= maxReadLines {
+		end = start + maxReadLines - 1
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	var out strings.Builder
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for n := 1; scanner.Scan() && n <= end; n++ {
+		if n >= start {
+			fmt.Fprintf(&out, "%5d  %s\n", n, scanner.Text())
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return "", err
+	}
+	if out.Len() == 0 {
+		return "", fmt.Errorf("%s has no lines from %d", args["path"], start)
+	}
+	return out.String(), nil
+}
+
+// goDoc runs go doc for one package or symbol
+func (s *Sandbox) goDoc(ctx context.Context, args map[string]string) (string, error) {
+	symbol := strings.TrimSpace(args["symbol"])
+	if symbol == "" || strings.HasPrefix(symbol, "-") || strings.ContainsAny(symbol, " \t\n") {
+		return "", fmt.Errorf("invalid symbol %q", symbol)
+	}
+	return s.goCommand(ctx, "doc", symbol)
+}
+
+// envName is what a go env variable name looks like
+var envName = regexp.MustCompile(`^[A-Z][A-Z0-9_]*$`)
+
+// goEnv runs go env for the given variables, or all of them
+func (s *Sandbox) goEnv(ctx context.Context, args map[string]string) (string, error) {
+	names := strings.Fields(args["names"])
+	for _, name := range names {
+		if !envName.MatchString(name) {
+			return "", fmt.Errorf("invalid variable name %q", name)
+		}
+	}
+	return s.goCommand(ctx, append([]string{"env"}, names...)...)
+}
+
+// goCommand runs the go tool in the project without touching the network or go.mod
+func (s *Sandbox) goCommand(ctx context.Context, args ...string) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, cmdTimeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, "go", args...)
+	cmd.Dir = s.root
+	cmd.Env = append(os.Environ(), "GOFLAGS=-mod=readonly", "GOPROXY=off", "GOTOOLCHAIN=local")
+
+	var out bytes.Buffer
+	cmd.Stdout, cmd.Stderr = &out, &out
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(out.String()); msg != "" {
+			return "", fmt.Errorf("%w: %s", err, truncate(msg))
+		}
+		return "", err
+	}
+	return out.String(), nil
+}
+
+// listSymbols lists a package's top-level declarations
+func (s *Sandbox) listSymbols(ctx context.Context, args map[string]string) (string, error) {
+	dir, err := s.resolve(args["dir"])
+	if err != nil {
+		return "", err
+	}
+
+	fset := token.NewFileSet()
+	pkgs, err := parser.ParseDir(fset, dir, func(info fs.FileInfo) bool {
+		return !strings.HasSuffix(info.Name(), "_test.go")
+	}, parser.SkipObjectResolution)
+	if err != nil {
+		return "", err
+	}
+	if len(pkgs) == 0 {
+		return "", fmt.Errorf("no Go files in %s", args["dir"])
+	}
+
+	var lines []string
+	for _, pkg := range pkgs {
+		for _, file := range pkg.Files {
+			for _, decl := range file.Decls {
+				lines = append(lines, declSymbols(fset, s.rel(fset.Position(decl.Pos()).Filename), decl)...)
+			}
+		}
+	}
+	sort.Strings(lines)
+	return strings.Join(lines, "\n") + "\n", nil
+}
+
+// declSymbols describes the names one declaration introduces
+func declSymbols(fset *token.FileSet, file string, decl ast.Decl) []string {
+	at := func(pos token.Pos) string {
+		return fmt.Sprintf("%s:%d", file, fset.Position(pos).Line)
+	}
+
+	switch d := decl.(type) {
+	case *ast.FuncDecl:
+		name := d.Name.Name
+		if d.Recv != nil && len(d.Recv.List) > 0 {
+			name = "(" + receiverType(d.Recv.List[0].Type) + ") " + name
+		}
+		return []string{fmt.Sprintf("%s  func %s", at(d.Pos()), name)}
+	case *ast.GenDecl:
+		var out []string
+		for _, spec := range d.Specs {
+			switch sp := spec.(type) {
+			case *ast.TypeSpec:
+				out = append(out, fmt.Sprintf("%s  type %s", at(sp.Pos()), sp.Name.Name))
+			case *ast.ValueSpec:
+				for _, name := range sp.Names {
+					out = append(out, fmt.Sprintf("%s  %s %s", at(name.Pos()), d.Tok, name.Name))
+				}
+			}
+		}
+		return out
+	}
+	return nil
+}
+
+// receiverType returns the name of a method's receiver type, e.g. *Store
+func receiverType(expr ast.Expr) string {
+	switch e := expr.(type) {
+	case *ast.StarExpr:
+		return "*" + receiverType(e.X)
+	case *ast.Ident:
+		return e.Name
+	case *ast.IndexExpr:
+		return receiverType(e.X)
+	case *ast.IndexListExpr:
+		return receiverType(e.X)
+	}
+	return "?"
+}
+
+// grep finds lines matching a pattern under a path
+func (s *Sandbox) grep(ctx context.Context, args map[string]string) (string, error) {
+	pattern, err := regexp.Compile(args["pattern"])
+	if err != nil || args["pattern"] == "" {
+		return "", fmt.Errorf("invalid pattern %q", args["pattern"])
+	}
+	start, err := s.resolve(args["path"])
+	if err != nil {
+		return "", err
+	}
+
+	var out strings.Builder
+	hits := 0
+	walkErr := filepath.WalkDir(start, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil || ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if entry.IsDir() {
+			switch entry.Name() {
+			case ".git", "vendor", "node_modules":
+				if path != start {
+					return filepath.SkipDir
+				}
+			}
+			return nil
+		}
+		if !entry.Type().IsRegular() {
+			return nil
+		}
+		if info, err := entry.Info(); err != nil || info.Size() > maxGrepFile {
+			return nil
+		}
+
+		data, err := os.ReadFile(path)
+		if err != nil || bytes.IndexByte(data[:min(len(data), 512)], 0) >= 0 {
+			return nil // unreadable or binary
+		}
+		for n, line := range strings.Split(string(data), "\n") {
+			if !pattern.MatchString(line) {
+				continue
+			}
+			if len(line) > 200 {
+				line = line[:200] + "…"
+			}
+			fmt.Fprintf(&out, "%s:%d: %s\n", s.rel(path), n+1, strings.TrimSpace(line))
+			if hits++; hits >= maxGrepHits {
+				fmt.Fprintf(&out, "(stopped after %d matches)\n", maxGrepHits)
+				return filepath.SkipAll
+			}
+		}
+		return nil
+	})
+	if walkErr != nil {
+		return "", walkErr
+	}
+	if hits == 0 {
+		return "no matches\n", nil
+	}
+	return out.String(), nil
+}
+
+// resolve turns a path from the AI into a real path inside the sandbox
+func (s *Sandbox) resolve(path string) (string, error) {
+	if path == "" {
+		path = "."
+	}
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(s.root, path)
+	}
+
+	real, err := filepath.EvalSymlinks(path)
+	if err != nil {
+		return "", err
+	}
+	rel, err := filepath.Rel(s.root, real)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", ErrOutsideSandbox
+	}
+	return real, nil
+}
+
+// rel returns path relative to the sandbox root, for output
+func (s *Sandbox) rel(path string) string {
+	if rel, err := filepath.Rel(s.root, path); err == nil {
+		return filepath.ToSlash(rel)
+	}
+	return path
+}
+
+// lineArg reads a line number argument, or returns def when it is missing
+func lineArg(args map[string]string, name string, def int) (int, error) {
+	value := strings.TrimSpace(args[name])
+	if value == "" {
+		return def, nil
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil || n < 1 {
+		return 0, fmt.Errorf("invalid %s %q", name, value)
+	}
+	return n, nil
+}
+
+// truncate cuts output that would crowd out the conversation
+func truncate(out string) string {
+	if len(out) <= maxOutput {
+		return out
+	}
+	return out[:maxOutput] + "\n(output cut short)\n"
+}
@@ -0,0 +1,142 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// newTestSandbox makes a small Go package in a temp dir
+func newTestSandbox(t *testing.T) (*Sandbox, string) {
+	t.Helper()
+	dir := t.TempDir()
+	files := map[string]string{
+		"go.mod":           "module example.com/demo\n\ngo 1.21\n",
+		"app/app.go":       "package app\n\n// Store keeps things\ntype Store struct{}\n\nconst Limit = 3\n\nfunc (s *Store) Load() error { return nil }\n\nfunc New() *Store { return &Store{} }\n",
+		"app/app_test.go":  "package app\n\nfunc helper() {}\n",
+		"vendor/x/x.go":    "package x\n\nfunc Load() {}\n",
+		"notes/secret.txt": "nothing here\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	sandbox, err := NewSandbox(dir)
+	if err != nil {
+		t.Fatalf("NewSandbox returned error: %v", err)
+	}
+	return sandbox, dir
+}
+
+// run calls the named tool
+func run(t *testing.T, sandbox *Sandbox, name string, args map[string]string) (string, error) {
+	t.Helper()
+	for _, tool := range sandbox.Tools() {
+		if tool.Name() == name {
+			return tool.Run(context.Background(), args)
+		}
+	}
+	t.Fatalf("no tool %q", name)
+	return "", nil
+}
+
+func TestReadFile(t *testing.T) {
+	sandbox, _ := newTestSandbox(t)
+
+	out, err := run(t, sandbox, "read_file", map[string]string{"path": "app/app.go", "start": "3", "end": "4"})
+	if err != nil {
+		t.Fatalf("read_file returned error: %v", err)
+	}
+	if out != "    3  // Store keeps things\n    4  type Store struct{}\n" {
+		t.Errorf("read_file = %q", out)
+	}
+
+	if _, err := run(t, sandbox, "read_file", map[string]string{"path": "app/app.go", "start": "x"}); err == nil {
+		t.Error("read_file should reject a bad line number")
+	}
+}
+
+func TestPathsStayInSandbox(t *testing.T) {
+	sandbox, dir := newTestSandbox(t)
+
+	outside := filepath.Join(t.TempDir(), "outside.txt")
+	if err := os.WriteFile(outside, []byte("private\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(outside, filepath.Join(dir, "link.txt")); err != nil {
+		t.Skipf("symlinks not available: %v", err)
+	}
+
+	for _, path := range []string{"../outside.txt", outside, "link.txt"} {
+		if _, err := run(t, sandbox, "read_file", map[string]string{"path": path}); err == nil || (path != "../outside.txt" && !errors.Is(err, ErrOutsideSandbox)) {
+			t.Errorf("read_file(%q) = %v, want it refused", path, err)
+		}
+	}
+}
+
+func TestListSymbols(t *testing.T) {
+	sandbox, _ := newTestSandbox(t)
+
+	out, err := run(t, sandbox, "list_symbols", map[string]string{"dir": "app"})
+	if err != nil {
+		t.Fatalf("list_symbols returned error: %v", err)
+	}
+	for _, want := range []string{"app/app.go:4  type Store", "app/app.go:6  const Limit", "app/app.go:8  func (*Store) Load", "app/app.go:10  func New"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("list_symbols = %q, want it to contain %q", out, want)
+		}
+	}
+	if strings.Contains(out, "helper") {
+		t.Errorf("list_symbols should skip test files, got %q", out)
+	}
+}
+
+func TestGrep(t *testing.T) {
+	sandbox, _ := newTestSandbox(t)
+
+	out, err := run(t, sandbox, "grep", map[string]string{"pattern": `func .*Load`})
+	if err != nil {
+		t.Fatalf("grep returned error: %v", err)
+	}
+	if out != "app/app.go:8: func (s *Store) Load() error { return nil }\n" {
+		t.Errorf("grep = %q, want only the app match (vendor skipped)", out)
+	}
+
+	out, err = run(t, sandbox, "grep", map[string]string{"pattern": "nowhere"})
+	if err != nil || out != "no matches\n" {
+		t.Errorf("grep without matches = %q, %v", out, err)
+	}
+}
+
+func TestGoToolArgumentsChecked(t *testing.T) {
+	sandbox, _ := newTestSandbox(t)
+
+	if _, err := run(t, sandbox, "go_doc", map[string]string{"symbol": "-u"}); err == nil {
+		t.Error("go_doc should refuse flags")
+	}
+	if _, err := run(t, sandbox, "go_env", map[string]string{"names": "GOOS; rm"}); err == nil {
+		t.Error("go_env should refuse odd names")
+	}
+}
+
+func TestSelect(t *testing.T) {
+	sandbox, _ := newTestSandbox(t)
+
+	selected := Select(sandbox.Tools(), []string{"grep", "go_env"})
+	var names []string
+	for _, tool := range selected {
+		names = append(names, tool.Name())
+	}
+	if strings.Join(names, ",") != "read_file,go_doc,list_symbols" {
+		t.Errorf("Select = %v", names)
+	}
+}
@@ -0,0 +1,68 @@
+package usage
+
+import "strings"
+
+// Price is what a model costs in US dollars per million tokens
+type Price struct {
+	Input  float64 `json:"input" mapstructure:"input"`
+	Output float64 `json:"output" mapstructure:"output"`
+}
+
+// PriceTable maps model names to prices. A key also covers every model whose
+// name starts with it, so "gpt-4o" prices "gpt-4o-2024-08-06"; the longest key wins.
+type PriceTable map[string]Price
+
+// freeProviders run on the user's own machine
+var freeProviders = map[string]bool{
+	"ollama":   true,
+	"llamacpp": true,
+	"offline":  true,
+}
+
+// DefaultPrices returns list prices for the hosted models GuruUI is used with most
+func DefaultPrices() PriceTable {
+	return PriceTable{
+		"gpt-3.5-turbo":     {Input: 0.50, Output: 1.50},
+		"gpt-4":             {Input: 30, Output: 60},
+		"gpt-4-turbo":       {Input: 10, Output: 30},
+		"gpt-4o":            {Input: 2.50, Output: 10},
+		"gpt-4o-mini":       {Input: 0.15, Output: 0.60},
+		"gpt-4.1":           {Input: 2, Output: 8},
+		"gpt-4.1-mini":      {Input: 0.40, Output: 1.60},
+		"claude-3-haiku":    {Input: 0.25, Output: 1.25},
+		"claude-3-sonnet":   {Input: 3, Output: 15},
+		"claude-3-opus":     {Input: 15, Output: 75},
+		"claude-3-5-haiku":  {Input: 0.80, Output: 4},
+		"claude-3-5-sonnet": {Input: 3, Output: 15},
+		"claude-3-7-sonnet": {Input: 3, Output: 15},
+	}
+}
+
+// Cost returns what a request cost, and false when the model has no price.
+// Local providers are always free.
+func (t PriceTable) Cost(provider, model string, inputTokens, outputTokens int) (float64, bool) {
+	if freeProviders[provider] {
+		return 0, true
+	}
+
+	price, ok := t.lookup(model)
+	if !ok {
+		return 0, false
+	}
+	return (float64(inputTokens)*price.Input + float64(outputTokens)*price.Output) / 1e6, true
+}
+
+// lookup finds the price for model by exact name or longest matching prefix
+func (t PriceTable) lookup(model string) (Price, bool) {
+	if price, ok := t[model]; ok {
+		return price, true
+	}
+
+	best, found := "", false
+	for name := range t {
+		if strings.HasPrefix(model, name) && len(name) > len(best) {
+			best, found = name, true
+		}
+	}
+	return t[best], found
+}
@@ -0,0 +1,159 @@
+package usage
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/arnislvdev/go-guru-ui/internal/infrastructure/ai"
+)
+
+// dayFormat is how days are written in the usage file
+const dayFormat = "2006-01-02"
+
+// Row is the total for one day, command and model
+type Row struct {
+	Day          string  `json:"day"`
+	Command      string  `json:"command"`
+	Provider     string  `json:"provider"`
+	Model        string  `json:"model"`
+	Requests     int     `json:"requests"`
+	InputTokens  int     `json:"input_tokens"`
+	OutputTokens int     `json:"output_tokens"`
+	Cost         float64 `json:"cost"`
+	Unpriced     int     `json:"unpriced,omitempty"`  // requests to models without a known price
+	Estimated    int     `json:"estimated,omitempty"` // requests whose tokens were estimated
+}
+
+// Store keeps daily usage totals in a JSON file
+type Store struct {
+	path string
+}
+
+// DefaultPath returns where usage is kept: the user config dir plus guruui/usage.json
+func DefaultPath() (string, error) {
+	base, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to find config directory: %w", err)
+	}
+	return filepath.Join(base, "guruui", "usage.json"), nil
+}
+
+// NewStore returns a store kept in the file at path. The file is created on first use.
+func NewStore(path string) *Store {
+	return &Store{path: path}
+}
+
+// Add adds requests made by command at the given time to that day's totals,
+// pricing them with prices
+func (s *Store) Add(at time.Time, command string, records []ai.Usage, prices PriceTable) error {
+	if len(records) == 0 {
+		return nil
+	}
+
+	rows, err := s.load()
+	if err != nil {
+		return err
+	}
+
+	day := at.Format(dayFormat)
+	for _, record := range records {
+		row := findRow(&rows, day, command, record.Provider, record.Model)
+		row.Requests++
+		row.InputTokens += record.InputTokens
+		row.OutputTokens += record.OutputTokens
+		if record.Estimated {
+			row.Estimated++
+		}
+		if cost, ok := prices.Cost(record.Provider, record.Model, record.InputTokens, record.OutputTokens); ok {
+			row.Cost += cost
+		} else {
+			row.Unpriced++
+		}
+	}
+
+	return s.save(rows)
+}
+
+// Rows returns the totals from since onwards, newest day first
+func (s *Store) Rows(since time.Time) ([]Row, error) {
+	rows, err := s.load()
+	if err != nil {
+		return nil, err
+	}
+
+	first := since.Format(dayFormat)
+	var recent []Row
+	for _, row := range rows {
+		if row.Day >= first {
+			recent = append(recent, row)
+		}
+	}
+
+	sort.Slice(recent, func(i, j int) bool {
+		a, b := recent[i], recent[j]
+		if a.Day != b.Day {
+			return a.Day > b.Day
+		}
+		if a.Command != b.Command {
+			return a.Command < b.Command
+		}
+		return a.Provider+"/"+a.Model < b.Provider+"/"+b.Model
+	})
+	return recent, nil
+}
+
+// findRow returns the row for the key, adding it if it is new
+func findRow(rows *[]Row, day, command, provider, model string) *Row {
+	for i := range *rows {
+		row := &(*rows)[i]
+		if row.Day == day && row.Command == command && row.Provider == provider && row.Model == model {
+			return row
+		}
+	}
+	*rows = append(*rows, Row{Day: day, Command: command, Provider: provider, Model: model})
+	return &(*rows)[len(*rows)-1]
+}
+
+// load reads every row; a missing file means no usage yet
+func (s *Store) load() ([]Row, error) {
+	data, err := os.ReadFile(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read usage file: %w", err)
+	}
+
+	var rows []Row
+	if err := json.Unmarshal(data, &rows); err != nil {
+		return nil, fmt.Errorf("failed to parse usage file %s: %w", s.path, err)
+	}
+	return rows, nil
+}
+
+// save writes every row, through a temporary file so a crash never leaves half a file
+func (s *Store) save(rows []Row) error {
+	if err := os.MkdirAll(filepath.Dir(s.path), 0o700); err != nil {
+		return fmt.Errorf("failed to create usage directory: %w", err)
+	}
+
+	data, err := json.MarshalIndent(rows, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode usage: %w", err)
+	}
+
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return fmt.Errorf("failed to write usage file: %w", err)
+	}
+	if err := os.Rename(tmp, s.path); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("failed to write usage file: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,70 @@
+package usage
+
+import (
+	"math"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/arnislvdev/go-guru-ui/internal/infrastructure/ai"
+)
+
+func TestPriceTableCost(t *testing.T) {
+	prices := DefaultPrices()
+
+	tests := []struct {
+		provider, model string
+		want            float64
+		known           bool
+	}{
+		{"openai", "gpt-4o-2024-08-06", 2.50 + 10, true}, // longest prefix, not gpt-4
+		{"openai", "gpt-4", 30 + 60, true},
+		{"anthropic", "claude-3-5-sonnet-latest", 3 + 15, true},
+		{"ollama", "llama3", 0, true},
+		{"openai", "my-finetune", 0, false},
+	}
+	for _, tt := range tests {
+		cost, known := prices.Cost(tt.provider, tt.model, 1_000_000, 1_000_000)
+		if known != tt.known || math.Abs(cost-tt.want) > 1e-9 {
+			t.Errorf("Cost(%s, %s) = %v, %v; want %v, %v", tt.provider, tt.model, cost, known, tt.want, tt.known)
+		}
+	}
+}
+
+func TestStoreAddsDailyTotals(t *testing.T) {
+	store := NewStore(filepath.Join(t.TempDir(), "usage", "usage.json"))
+	prices := PriceTable{"gpt-4o": {Input: 2, Output: 10}}
+	day1 := time.Date(2024, 5, 1, 9, 0, 0, 0, time.Local)
+	day2 := day1.Add(24 * time.Hour)
+
+	gpt := ai.Usage{Provider: "openai", Model: "gpt-4o", InputTokens: 1000, OutputTokens: 500}
+	mystery := ai.Usage{Provider: "openai", Model: "mystery", InputTokens: 10, OutputTokens: 10}
+
+	if err := store.Add(day1, "explain", []ai.Usage{gpt, gpt}, prices); err != nil {
+		t.Fatalf("Add returned error: %v", err)
+	}
+	if err := store.Add(day2, "explain", []ai.Usage{gpt, mystery}, prices); err != nil {
+		t.Fatalf("Add returned error: %v", err)
+	}
+
+	rows, err := store.Rows(day1)
+	if err != nil {
+		t.Fatalf("Rows returned error: %v", err)
+	}
+	if len(rows) != 3 {
+		t.Fatalf("rows = %+v, want 3", rows)
+	}
+	if rows[0].Day != "2024-05-02" || rows[2].Day != "2024-05-01" {
+		t.Errorf("rows should be newest first: %+v", rows)
+	}
+	if older := rows[2]; older.Requests != 2 || older.InputTokens != 2000 || math.Abs(older.Cost-0.014) > 1e-9 {
+		t.Errorf("day 1 = %+v, want 2 requests, 2000 input tokens, $0.014", older)
+	}
+	if rows[1].Model != "mystery" || rows[1].Unpriced != 1 {
+		t.Errorf("unpriced row = %+v", rows[1])
+	}
+
+	if rows, _ := store.Rows(day2); len(rows) != 2 {
+		t.Errorf("Rows(day2) = %+v, want only day 2", rows)
+	}
+}
@@ -0,0 +1,93 @@
+package parser
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/arnislvdev/go-guru-ui/internal/domain"
+)
+
+// eslintProblem matches a problem under a file in ESLint's default "stylish"
+// output: "  12:5  error  'x' is not defined  no-undef". The rule is missing
+// for parsing errors.
+var eslintProblem = regexp.MustCompile(`^\s+(\d+):(\d+)\s+(error|warning)\s+(.+?)(?:\s{2,}(\S+))?$`)
+
+// eslintRules maps core rules, and the plugin rules that stand in for them,
+// to error types
+var eslintRules = map[string]string{
+	"no-undef":                         domain.ErrorTypeUndefinedSymbol,
+	"no-unused-vars":                   domain.ErrorTypeUnusedVariable,
+	"unused-imports/no-unused-vars":    domain.ErrorTypeUnusedVariable,
+	"unused-imports/no-unused-imports": domain.ErrorTypeUnusedImport,
+	"import/no-unresolved":             domain.ErrorTypeImport,
+	"import/named":                     domain.ErrorTypeImport,
+	"n/no-missing-import":              domain.ErrorTypeImport,
+	"n/no-missing-require":             domain.ErrorTypeImport,
+	"consistent-return":                domain.ErrorTypeMissingReturn,
+	"getter-return":                    domain.ErrorTypeMissingReturn,
+	"array-callback-return":            domain.ErrorTypeMissingReturn,
+	"no-unsafe-optional-chaining":      domain.ErrorTypeNullReference,
+}
+
+// ESLint parses the output of ESLint's default formatter into one error per
+// problem. Each file's name is on a line of its own, followed by its
+// problems; the summary at the end is skipped.
+func ESLint(output string) BuildOutput {
+	var result BuildOutput
+	var file string
+
+	for _, raw := range strings.Split(strings.ReplaceAll(output, "\r\n", "\n"), "\n") {
+		raw = ansiColor.ReplaceAllString(raw, "")
+		line := strings.TrimSpace(raw)
+		if line == "" {
+			continue
+		}
+
+		if !strings.HasPrefix(raw, " ") {
+			// A file name, or the "✖ 3 problems" summary
+			file = ""
+			if !strings.HasPrefix(line, "✖") && !strings.Contains(line, ": ") {
+				file = line
+			}
+			continue
+		}
+
+		match := eslintProblem.FindStringSubmatch(raw)
+		if match == nil || file == "" {
+			continue
+		}
+		lineNo, _ := strconv.Atoi(match[1])
+		column, _ := strconv.Atoi(match[2])
+		severity := domain.SeverityError
+		if match[3] == "warning" {
+			severity = domain.SeverityWarning
+		}
+
+		result.Errors = append(result.Errors, &domain.Error{
+			Message:  match[4],
+			Type:     classifyESLintRule(match[5], match[4]),
+			File:     file,
+			Line:     lineNo,
+			Column:   column,
+			Code:     match[5],
+			Severity: severity,
+			Language: scriptLanguage(file),
+		})
+	}
+
+	result.Errors = dedupe(result.Errors)
+	return result
+}
+
+// classifyESLintRule picks the error type for a rule. typescript-eslint's
+// versions of core rules count as the core rule.
+func classifyESLintRule(rule, message string) string {
+	if rule == "" && strings.HasPrefix(message, "Parsing error:") {
+		return domain.ErrorTypeSyntax
+	}
+	if errorType, ok := eslintRules[strings.TrimPrefix(rule, "@typescript-eslint/")]; ok {
+		return errorType
+	}
+	return domain.ErrorTypeUnknown
+}
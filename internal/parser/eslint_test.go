@@ -0,0 +1,55 @@
+package parser
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/arnislvdev/go-guru-ui/internal/domain"
+)
+
+func TestESLint(t *testing.T) {
+	output := ESLint(readTestdata(t, "eslint.txt"))
+
+	want := []*domain.Error{
+		{
+			Message: "'useMemo' is defined but never used", Type: domain.ErrorTypeUnusedVariable,
+			File: "/home/dev/web/src/App.tsx", Line: 3, Column: 10, Code: "@typescript-eslint/no-unused-vars",
+			Severity: domain.SeverityError, Language: domain.LanguageTS,
+		},
+		{
+			Message: "Unexpected console statement", Type: domain.ErrorTypeUnknown,
+			File: "/home/dev/web/src/App.tsx", Line: 27, Column: 5, Code: "no-console",
+			Severity: domain.SeverityWarning, Language: domain.LanguageTS,
+		},
+		{
+			Message: "Parsing error: ')' expected", Type: domain.ErrorTypeSyntax,
+			File: "/home/dev/web/src/App.tsx", Line: 41, Column: 12,
+			Severity: domain.SeverityError, Language: domain.LanguageTS,
+		},
+		{
+			Message: "'process' is not defined", Type: domain.ErrorTypeUndefinedSymbol,
+			File: "/home/dev/web/src/util.js", Line: 8, Column: 3, Code: "no-undef",
+			Severity: domain.SeverityError, Language: domain.LanguageJS,
+		},
+	}
+	if !reflect.DeepEqual(output.Errors, want) {
+		for _, err := range output.Errors {
+			t.Logf("got %+v", *err)
+		}
+		t.Error("ESLint output parsed wrong")
+	}
+}
+
+func TestDiagnosticsMixesTools(t *testing.T) {
+	input := "# example.com/app\n./main.go:3:2: undefined: x\n" + readTestdata(t, "tsc.txt") + readTestdata(t, "eslint.txt")
+	output := Diagnostics(input)
+
+	languages := make(map[string]int)
+	for _, err := range output.Errors {
+		languages[err.Language]++
+	}
+	want := map[string]int{domain.LanguageGo: 1, domain.LanguageTS: 9, domain.LanguageJS: 1}
+	if !reflect.DeepEqual(languages, want) {
+		t.Errorf("errors by language = %v, want %v", languages, want)
+	}
+}
@@ -0,0 +1,132 @@
+// Package parser reads compiler and runtime output into structured errors
+package parser
+
+import (
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/arnislvdev/go-guru-ui/internal/domain"
+)
+
+var (
+	// goPosition matches "path/file.go:12:5: message", with the column optional
+	goPosition = regexp.MustCompile(`^(.+?\.go):(\d+)(?::(\d+))?: (.*)$`)
+
+	// goProgress matches what the go command reports as it fetches and updates
+	// modules, as in "go: downloading example.com/lib v1.2.0"
+	goProgress = regexp.MustCompile(`^go: (?:downloading|finding|extracting|found|added|upgraded|downgraded|removed) `)
+)
+
+// BuildOutput is what GoBuild found in a run of the Go toolchain
+type BuildOutput struct {
+	Errors []*domain.Error
+
+	// Truncated is set when the compiler stopped early with "too many errors"
+	Truncated bool
+}
+
+// GoBuild parses the output of go build, go vet or go test into one error per
+// diagnostic. "# pkg" headers set each error's Package, indented lines that
+// follow a diagnostic (such as "have/want" notes) are added to its message,
+// and repeated diagnostics are kept once. Failures reported by the go command
+// itself ("go: ...") become errors without a file; its progress lines, such as
+// "go: downloading ...", are skipped.
+func GoBuild(output string) BuildOutput {
+	var result BuildOutput
+	var pkg string
+	var last *domain.Error
+
+	add := func(err *domain.Error) {
+		result.Errors = append(result.Errors, err)
+		last = err
+	}
+
+	for _, line := range strings.Split(strings.ReplaceAll(output, "\r\n", "\n"), "\n") {
+		// Continuation lines are indented with a tab
+		if strings.HasPrefix(line, "\t") && last != nil {
+			last.Message += "\n" + strings.TrimSpace(line)
+			continue
+		}
+
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		if strings.HasPrefix(line, "# ") {
+			pkg = strings.TrimSpace(strings.TrimPrefix(line, "# "))
+			last = nil
+			continue
+		}
+		line = strings.TrimPrefix(line, "vet: ")
+
+		match := goPosition.FindStringSubmatch(line)
+		if match == nil {
+			last = nil
+			if strings.HasPrefix(line, "go: ") && !goProgress.MatchString(line) {
+				add(&domain.Error{Message: line, Package: pkg, Language: domain.LanguageGo})
+			}
+			continue
+		}
+
+		message := match[4]
+		if message == "too many errors" {
+			result.Truncated = true
+			last = nil
+			continue
+		}
+
+		lineNo, _ := strconv.Atoi(match[2])
+		column, _ := strconv.Atoi(match[3])
+		add(&domain.Error{
+			Message:  message,
+			File:     match[1],
+			Line:     lineNo,
+			Column:   column,
+			Package:  pkg,
+			Language: domain.LanguageGo,
+		})
+	}
+
+	result.Errors = dedupe(result.Errors)
+	return result
+}
+
+// dedupe drops errors that are the same as an earlier one, as when go vet
+// and go build report the same problem
+func dedupe(errs []*domain.Error) []*domain.Error {
+	type position struct {
+		file         string
+		line, column int
+		message      string
+	}
+	seen := make(map[position]bool)
+	kept := errs[:0]
+	for _, err := range errs {
+		key := position{err.File, err.Line, err.Column, err.Message}
+		if !seen[key] {
+			seen[key] = true
+			kept = append(kept, err)
+		}
+	}
+	return kept
+}
+
+// SortByPosition orders errors by file, then line and column. Errors without
+// a file come last, in the order they were found.
+func SortByPosition(errs []*domain.Error) {
+	sort.SliceStable(errs, func(i, j int) bool {
+		a, b := errs[i], errs[j]
+		switch {
+		case a.File == "" || b.File == "":
+			return a.File != "" && b.File == ""
+		case a.File != b.File:
+			return a.File < b.File
+		case a.Line != b.Line:
+			return a.Line < b.Line
+		default:
+			return a.Column < b.Column
+		}
+	})
+}
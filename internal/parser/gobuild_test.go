@@ -0,0 +1,67 @@
+package parser
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/arnislvdev/go-guru-ui/internal/domain"
+)
+
+const buildOutput = `# example.com/app/store
+store/load.go:30:9: undefined: decode
+store/load.go:12:2: "os" imported and not used
+# example.com/app
+./main.go:20:14: cannot use n (variable of type int) as string value in argument to greet
+	have (int)
+	want (string)
+./main.go:8:2: declared and not used: x
+./main.go:20:14: cannot use n (variable of type int) as string value in argument to greet
+	have (int)
+	want (string)
+./main.go:40:1: too many errors
+go: downloading example.com/lib v1.2.0
+go: added example.com/lib v1.2.0
+go: example.com/lib@v1.2.0: verifying module: checksum mismatch
+vet: ./util.go:5: missing return
+`
+
+func TestGoBuild(t *testing.T) {
+	out := GoBuild(buildOutput)
+	if !out.Truncated {
+		t.Error("Truncated = false, want true after \"too many errors\"")
+	}
+
+	want := []domain.Error{
+		{Message: "undefined: decode", File: "store/load.go", Line: 30, Column: 9, Package: "example.com/app/store"},
+		{Message: `"os" imported and not used`, File: "store/load.go", Line: 12, Column: 2, Package: "example.com/app/store"},
+		{Message: "cannot use n (variable of type int) as string value in argument to greet\nhave (int)\nwant (string)", File: "./main.go", Line: 20, Column: 14, Package: "example.com/app"},
+		{Message: "declared and not used: x", File: "./main.go", Line: 8, Column: 2, Package: "example.com/app"},
+		{Message: "go: example.com/lib@v1.2.0: verifying module: checksum mismatch", Package: "example.com/app"},
+		{Message: "missing return", File: "./util.go", Line: 5, Package: "example.com/app"},
+	}
+	if len(out.Errors) != len(want) {
+		t.Fatalf("got %d errors, want %d: %+v", len(out.Errors), len(want), out.Errors)
+	}
+	for i, got := range out.Errors {
+		want[i].Language = domain.LanguageGo
+		if !reflect.DeepEqual(*got, want[i]) {
+			t.Errorf("error %d = %+v, want %+v", i, *got, want[i])
+		}
+	}
+}
+
+func TestSortByPosition(t *testing.T) {
+	errs := GoBuild(buildOutput).Errors
+	SortByPosition(errs)
+
+	var got []string
+	for _, err := range errs {
+		got = append(got, err.File+":"+err.Message[:4])
+	}
+	want := []string{"./main.go:decl", "./main.go:cann", "./util.go:miss", "store/load.go:\"os\"", "store/load.go:unde", ":go: "}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("order = %q, want %q", got, want)
+		}
+	}
+}
@@ -0,0 +1,222 @@
+package parser
+
+import (
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/arnislvdev/go-guru-ui/internal/domain"
+)
+
+// maxFrames caps how many calls of a stack are kept
+const maxFrames = 30
+
+var (
+	// goroutineHeader matches "goroutine 1 [running]:" and "goroutine 7 [chan receive, 3 minutes]:",
+	// with or without the "gp=0x... m=nil" details GOTRACEBACK=system adds
+	goroutineHeader = regexp.MustCompile(`^goroutine (\d+)(?: [^\[]*)? \[([^\]]*)\]:$`)
+
+	// frameLocation matches the "\t/path/file.go:12 +0x1d" line under each call, with the
+	// "fp=0x... sp=0x... pc=0x..." that SIGQUIT and GOTRACEBACK=system add
+	frameLocation = regexp.MustCompile(`^(.+?):(\d+)(?: \+0x[0-9a-f]+)?(?: fp=0x[0-9a-f]+ sp=0x[0-9a-f]+ pc=0x[0-9a-f]+)?$`)
+)
+
+// runtimeKinds maps phrases in panic and fatal error messages to what went
+// wrong, checked in order
+var runtimeKinds = []struct {
+	phrase string
+	kind   string
+}{
+	{"assignment to entry in nil map", domain.RuntimeNilMapWrite},
+	{"index out of range", domain.RuntimeIndexOutOfRange},
+	{"slice bounds out of range", domain.RuntimeSliceBounds},
+	{"nil pointer dereference", domain.RuntimeNilPointer},
+	{"invalid memory address", domain.RuntimeNilPointer},
+	{"close of closed channel", domain.RuntimeClosedChannel},
+	{"close of nil channel", domain.RuntimeNilChannel},
+	{"send on closed channel", domain.RuntimeSendOnClosed},
+	{"integer divide by zero", domain.RuntimeDivideByZero},
+	{"interface conversion", domain.RuntimeTypeAssertion},
+	{"concurrent map", domain.RuntimeConcurrentMap},
+	{"all goroutines are asleep", domain.RuntimeDeadlock},
+	{"stack overflow", domain.RuntimeStackOverflow},
+	{"goroutine stack exceeds", domain.RuntimeStackOverflow},
+	{"out of memory", domain.RuntimeOutOfMemory},
+}
+
+// GoPanic parses a panic or fatal runtime error with the stack trace that
+// follows it. The message is the "panic:" or "fatal error:" block, the stack
+// is the failing goroutine's, and File and Line point at the first call in
+// the program's own code, not the runtime. It reports false when text holds
+// no panic.
+func GoPanic(text string) (*domain.Error, bool) {
+	lines := strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n")
+
+	start := -1
+	for i, line := range lines {
+		line = strings.TrimSpace(line)
+		if strings.HasPrefix(line, "panic: ") || strings.HasPrefix(line, "fatal error: ") {
+			start = i
+			break
+		}
+	}
+	if start < 0 {
+		return nil, false
+	}
+
+	// The message runs until the first goroutine's stack
+	var message []string
+	stackAt := len(lines)
+	for i := start; i < len(lines); i++ {
+		line := strings.TrimSpace(lines[i])
+		if goroutineHeader.MatchString(line) {
+			stackAt = i
+			break
+		}
+		if line != "" {
+			message = append(message, line)
+		}
+	}
+
+	header := message[0]
+	err := &domain.Error{
+		Message:  strings.Join(message, "\n"),
+		Type:     domain.ErrorTypeRuntimePanic,
+		Severity: domain.SeverityFatal,
+		Language: domain.LanguageGo,
+	}
+
+	if value, ok := strings.CutPrefix(header, "panic: "); ok {
+		err.PanicValue = strings.TrimSuffix(value, " [recovered]")
+		err.RuntimeKind = runtimeKind(err.Message, domain.RuntimeCustomPanic)
+	} else {
+		err.PanicValue = strings.TrimPrefix(header, "fatal error: ")
+		err.RuntimeKind = runtimeKind(err.Message, "")
+	}
+	// "panic: runtime error: ..." comes from the runtime, not a panic call
+	if err.RuntimeKind == domain.RuntimeCustomPanic && strings.HasPrefix(err.PanicValue, "runtime error: ") {
+		err.RuntimeKind = ""
+	}
+
+	if stackAt < len(lines) {
+		err.Stack, _ = readStack(lines, stackAt+1)
+	}
+	if frame := FirstUserFrame(err.Stack); frame != nil {
+		err.File, err.Line = frame.File, frame.Line
+	}
+	return err, true
+}
+
+// runtimeKind returns the RuntimeKind the message describes, or fallback
+func runtimeKind(message, fallback string) string {
+	for _, k := range runtimeKinds {
+		if strings.Contains(message, k.phrase) {
+			return k.kind
+		}
+	}
+	return fallback
+}
+
+// readStack reads the calls of one goroutine starting at lines[i], up to the
+// next blank line or goroutine header. It returns the frames and the index of
+// the first line after them. "created by" lines are not calls and are skipped.
+func readStack(lines []string, i int) ([]domain.Frame, int) {
+	var frames []domain.Frame
+	for ; i < len(lines); i++ {
+		line := strings.TrimSpace(lines[i])
+		if line == "" || goroutineHeader.MatchString(line) {
+			break
+		}
+		// Locations are read with their call; elided frames aren't calls
+		if strings.HasPrefix(lines[i], "\t") || strings.HasPrefix(line, "...") || strings.HasPrefix(line, "created by ") {
+			continue
+		}
+
+		// Every call has its location on the next line; anything else isn't part of the stack
+		if i+1 >= len(lines) || !strings.HasPrefix(lines[i+1], "\t") {
+			continue
+		}
+		match := frameLocation.FindStringSubmatch(strings.TrimSpace(lines[i+1]))
+		if match == nil {
+			continue
+		}
+		i++
+
+		frame := domain.Frame{Function: stripArgs(line), File: match[1]}
+		frame.Line, _ = strconv.Atoi(match[2])
+		frame.User = isUserCode(frame.Function, frame.File)
+		if len(frames) < maxFrames {
+			frames = append(frames, frame)
+		}
+	}
+	return frames, i
+}
+
+// FirstUserFrame returns the innermost call in the program's own code, or
+// the innermost call of all when none is, or nil for an empty stack
+func FirstUserFrame(stack []domain.Frame) *domain.Frame {
+	for i := range stack {
+		if stack[i].User {
+			return &stack[i]
+		}
+	}
+	if len(stack) > 0 {
+		return &stack[0]
+	}
+	return nil
+}
+
+// stripArgs drops the argument list from a call like "main.(*T).run(0xc000010000, {0x0, 0x0})"
+func stripArgs(call string) string {
+	if !strings.HasSuffix(call, ")") {
+		return call
+	}
+	depth := 0
+	for i := len(call) - 1; i >= 0; i-- {
+		switch call[i] {
+		case ')':
+			depth++
+		case '(':
+			depth--
+			if depth == 0 {
+				return call[:i]
+			}
+		}
+	}
+	return call
+}
+
+// isUserCode reports whether a function belongs to the program rather than
+// the runtime or standard library. Standard packages have no dot in their
+// first path element and live under GOROOT's src folder, or, in a binary
+// built with -trimpath, at their import path.
+func isUserCode(function, file string) bool {
+	pkg := packagePath(function)
+	// Functions like sync.runtime_Semacquire are the runtime under another package's name
+	if pkg == "" || strings.Contains(function, ".runtime_") {
+		return false
+	}
+	if pkg == "main" {
+		return true
+	}
+
+	first, _, _ := strings.Cut(pkg, "/")
+	if strings.Contains(first, ".") {
+		return true
+	}
+	// A module named without a dot ("myapp/internal/store") isn't under GOROOT.
+	// With -trimpath such a module looks just like the standard library.
+	file = filepath.ToSlash(file)
+	return file != "" && !strings.Contains(file, "/src/"+pkg+"/") && !strings.HasPrefix(file, pkg+"/")
+}
+
+// packagePath returns the import path of a function like "github.com/a/b.(*T).M"
+func packagePath(function string) string {
+	slash := strings.LastIndexByte(function, '/')
+	dot := strings.IndexByte(function[slash+1:], '.')
+	if dot < 0 {
+		return ""
+	}
+	return function[:slash+1+dot]
+}
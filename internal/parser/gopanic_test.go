@@ -0,0 +1,119 @@
+package parser
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/arnislvdev/go-guru-ui/internal/domain"
+)
+
+func readTestdata(t *testing.T, name string) string {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+func TestGoPanicNilMap(t *testing.T) {
+	err, ok := GoPanic(readTestdata(t, "nil_map.txt"))
+	if !ok {
+		t.Fatal("GoPanic found no panic")
+	}
+
+	if err.Message != "panic: assignment to entry in nil map" || err.PanicValue != "assignment to entry in nil map" {
+		t.Errorf("message = %q, value = %q", err.Message, err.PanicValue)
+	}
+	if err.RuntimeKind != domain.RuntimeNilMapWrite || err.Type != domain.ErrorTypeRuntimePanic || err.Severity != domain.SeverityFatal {
+		t.Errorf("kind = %q, type = %q, severity = %q", err.RuntimeKind, err.Type, err.Severity)
+	}
+
+	want := []domain.Frame{
+		{Function: "example.com/app/store.(*Index).Add", File: "/home/dev/app/store/index.go", Line: 42, User: true},
+		{Function: "example.com/app/store.Load.func1", File: "/home/dev/app/store/load.go", Line: 18, User: true},
+	}
+	if len(err.Stack) != len(want) {
+		t.Fatalf("stack = %+v, want %+v", err.Stack, want)
+	}
+	for i := range want {
+		if err.Stack[i] != want[i] {
+			t.Errorf("frame %d = %+v, want %+v", i, err.Stack[i], want[i])
+		}
+	}
+	if err.File != "/home/dev/app/store/index.go" || err.Line != 42 {
+		t.Errorf("location = %s:%d, want the Add call", err.File, err.Line)
+	}
+}
+
+func TestGoPanicPointsAtUserCode(t *testing.T) {
+	err, ok := GoPanic(readTestdata(t, "nil_pointer.txt"))
+	if !ok {
+		t.Fatal("GoPanic found no panic")
+	}
+
+	if err.RuntimeKind != domain.RuntimeNilPointer {
+		t.Errorf("kind = %q, want %q", err.RuntimeKind, domain.RuntimeNilPointer)
+	}
+	if err.PanicValue != "runtime error: invalid memory address or nil pointer dereference" {
+		t.Errorf("value = %q", err.PanicValue)
+	}
+
+	// The runtime, testing and encoding/json frames are skipped
+	if err.File != "/work/myapp/internal/config/config.go" || err.Line != 31 {
+		t.Errorf("location = %s:%d, want config.go:31", err.File, err.Line)
+	}
+	var user []string
+	for _, frame := range err.Stack {
+		if frame.User {
+			user = append(user, frame.Function)
+		}
+	}
+	if len(err.Stack) != 6 || len(user) != 2 || user[0] != "myapp/internal/config.(*Config).Port" {
+		t.Errorf("stack = %+v, want 6 calls with the two config ones marked as user code", err.Stack)
+	}
+}
+
+func TestGoPanicTrimpath(t *testing.T) {
+	err, ok := GoPanic(readTestdata(t, "trimpath.txt"))
+	if !ok {
+		t.Fatal("GoPanic found no panic")
+	}
+
+	// -trimpath prints standard library files at their import path
+	if err.File != "example.com/shout/main.go" || err.Line != 11 {
+		t.Errorf("location = %s:%d, want main.go:11", err.File, err.Line)
+	}
+	if len(err.Stack) != 4 || err.Stack[0].User || err.Stack[2].User || !err.Stack[1].User {
+		t.Errorf("stack = %+v, want only the main calls marked as user code", err.Stack)
+	}
+}
+
+func TestGoPanicKinds(t *testing.T) {
+	tests := []struct {
+		text string
+		kind string
+	}{
+		{"panic: runtime error: index out of range [5] with length 3", domain.RuntimeIndexOutOfRange},
+		{"panic: runtime error: slice bounds out of range [:9] with capacity 4", domain.RuntimeSliceBounds},
+		{"panic: close of closed channel", domain.RuntimeClosedChannel},
+		{"panic: send on closed channel", domain.RuntimeSendOnClosed},
+		{"panic: runtime error: integer divide by zero", domain.RuntimeDivideByZero},
+		{"panic: interface conversion: interface {} is string, not int", domain.RuntimeTypeAssertion},
+		{"fatal error: concurrent map writes", domain.RuntimeConcurrentMap},
+		{"fatal error: all goroutines are asleep - deadlock!", domain.RuntimeDeadlock},
+		{"runtime: goroutine stack exceeds 1000000000-byte limit\nfatal error: stack overflow", domain.RuntimeStackOverflow},
+		{"panic: config file missing", domain.RuntimeCustomPanic},
+	}
+	for _, tt := range tests {
+		err, ok := GoPanic(tt.text)
+		if !ok || err.RuntimeKind != tt.kind {
+			t.Errorf("GoPanic(%q) kind = %v, want %q", tt.text, err, tt.kind)
+		}
+	}
+
+	if _, ok := GoPanic("undefined: fmt"); ok {
+		t.Error("GoPanic found a panic in a compiler error")
+	}
+}
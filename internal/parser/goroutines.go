@@ -0,0 +1,137 @@
+package parser
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/arnislvdev/go-guru-ui/internal/domain"
+)
+
+var (
+	// waitMinutes matches how long a goroutine has been blocked, as in "chan receive, 3 minutes"
+	waitMinutes = regexp.MustCompile(`^(\d+) minutes?$`)
+
+	// lockSlow matches the slow path every blocked Lock goes through, and its mutex, as in
+	// "internal/sync.(*Mutex).lockSlow(0xc000016a00)"; Lock itself is inlined and prints "(...)"
+	lockSlow = regexp.MustCompile(`^(?:internal/)?sync\.\(\*(?:RW)?Mutex\)\.(?:lockSlow|rUnlockSlow)\((0x[0-9a-f]+)[,)]`)
+
+	// rwSemacquire matches a blocked RWMutex Lock (waiting for readers) or RLock
+	// (waiting for a writer). Go before 1.17 prints the semaphore's address;
+	// later versions usually print a guess like "0x0?", which doesn't match.
+	rwSemacquire = regexp.MustCompile(`^sync\.runtime_SemacquireRWMutex(R?)\((0x[0-9a-f]+[,)])?`)
+
+	// semacquire matches the runtime's wait on a semaphore, whose address SIGQUIT
+	// and GOTRACEBACK=system print in full
+	semacquire = regexp.MustCompile(`^runtime\.semacquire1\((0x[0-9a-f]+)[,)]`)
+)
+
+// The offsets of RWMutex's writer and reader semaphores from the RWMutex,
+// which starts with an 8-byte Mutex
+const (
+	rwWriterSem = 8
+	rwReaderSem = 12
+)
+
+// Goroutine is one goroutine from a stack dump
+type Goroutine struct {
+	ID        int
+	State     string        // why it isn't running, e.g. "chan receive", "select", "IO wait"
+	Wait      time.Duration // how long it has been blocked; the runtime only reports whole minutes
+	Locked    bool          // locked to its OS thread
+	Stack     []domain.Frame
+	CreatedBy string // the function that started it, empty for the main goroutine
+	Mutex     string // address of the mutex it is waiting to lock, if any
+}
+
+// Dump is a parsed goroutine dump, as printed on SIGQUIT, by a fatal error
+// or by runtime.Stack with all set
+type Dump struct {
+	Goroutines []Goroutine
+
+	// Deadlock is set when the runtime itself reported that every goroutine is asleep
+	Deadlock bool
+}
+
+// GoroutineDump parses every goroutine in a dump. Lines before the first
+// goroutine, such as a "fatal error:" message, are only checked for the
+// runtime's deadlock report.
+func GoroutineDump(text string) Dump {
+	var dump Dump
+	lines := strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n")
+
+	for i := 0; i < len(lines); i++ {
+		line := strings.TrimSpace(lines[i])
+		if strings.Contains(line, "all goroutines are asleep - deadlock") {
+			dump.Deadlock = true
+		}
+
+		match := goroutineHeader.FindStringSubmatch(line)
+		if match == nil {
+			continue
+		}
+
+		g := Goroutine{}
+		g.ID, _ = strconv.Atoi(match[1])
+		for n, part := range strings.Split(match[2], ", ") {
+			switch {
+			case n == 0:
+				g.State = part
+			case part == "locked to thread":
+				g.Locked = true
+			case waitMinutes.MatchString(part):
+				minutes, _ := strconv.Atoi(waitMinutes.FindStringSubmatch(part)[1])
+				g.Wait = time.Duration(minutes) * time.Minute
+			}
+		}
+
+		var end int
+		g.Stack, end = readStack(lines, i+1)
+		var sema uint64
+		for _, raw := range lines[i+1 : end] {
+			raw = strings.TrimSpace(raw)
+			if creator, ok := strings.CutPrefix(raw, "created by "); ok {
+				creator, _, _ = strings.Cut(creator, " in goroutine ")
+				g.CreatedBy = creator
+			}
+			if g.Mutex != "" {
+				continue
+			}
+
+			if match := semacquire.FindStringSubmatch(raw); match != nil {
+				sema, _ = strconv.ParseUint(match[1], 0, 64)
+			}
+			if match := lockSlow.FindStringSubmatch(raw); match != nil {
+				g.Mutex = match[1]
+			}
+			if match := rwSemacquire.FindStringSubmatch(raw); match != nil {
+				g.Mutex = rwMutexAddress(match[2], sema, match[1] == "R")
+			}
+		}
+
+		dump.Goroutines = append(dump.Goroutines, g)
+		i = end - 1
+	}
+
+	return dump
+}
+
+// rwMutexAddress works out which RWMutex a goroutine waits on from the
+// semaphore it sleeps on: arg is what the runtime_SemacquireRWMutex call
+// printed, sema what runtime.semacquire1 printed, if anything. It returns ""
+// when neither is known, as with the default GOTRACEBACK since Go 1.17.
+func rwMutexAddress(arg string, sema uint64, reader bool) string {
+	if addr, _ := strconv.ParseUint(strings.TrimRight(arg, ",)"), 0, 64); addr != 0 {
+		sema = addr
+	}
+	offset := uint64(rwWriterSem)
+	if reader {
+		offset = rwReaderSem
+	}
+	if sema <= offset {
+		return ""
+	}
+	return fmt.Sprintf("%#x", sema-offset)
+}
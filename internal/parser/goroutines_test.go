@@ -0,0 +1,66 @@
+package parser
+
+import (
+	"testing"
+	"time"
+)
+
+func TestGoroutineDump(t *testing.T) {
+	// A real SIGQUIT dump: runtime goroutines, fp/sp/pc after each call and the registers at the end
+	dump := GoroutineDump(readTestdata(t, "sigquit.txt"))
+	if len(dump.Goroutines) != 66 || dump.Deadlock {
+		t.Fatalf("got %d goroutines (deadlock %v), want 66 and no deadlock", len(dump.Goroutines), dump.Deadlock)
+	}
+	byID := make(map[int]Goroutine)
+	for _, g := range dump.Goroutines {
+		byID[g.ID] = g
+	}
+
+	main := byID[1]
+	if main.State != "select" || main.Wait != 4*time.Minute || main.CreatedBy != "" || len(main.Stack) != 5 || main.Stack[2].Line != 39 {
+		t.Errorf("main goroutine = %+v", main)
+	}
+
+	worker := byID[10]
+	if worker.State != "chan receive" || worker.CreatedBy != "main.(*Pool).Start" || worker.Stack[3].Function != "main.(*Pool).worker" || worker.Stack[3].Line != 15 {
+		t.Errorf("worker = %+v", worker)
+	}
+
+	// The two transfers each wait on the lock the other holds
+	if a, b := byID[59], byID[60]; a.State != "sync.Mutex.Lock" || a.Mutex != "0x175edcb21d0" || b.Mutex != "0x175edcb2120" {
+		t.Errorf("transfers wait on %q and %q, want 0x175edcb21d0 and 0x175edcb2120", a.Mutex, b.Mutex)
+	}
+
+	// Lock and RLock sleep on different semaphores of the same RWMutex
+	if put, get := byID[61], byID[62]; put.State != "sync.RWMutex.Lock" || put.Mutex != "0x175edcf4000" || get.Mutex != "0x175edcf4000" {
+		t.Errorf("cache goroutines wait on %q and %q, want both on 0x175edcf4000", put.Mutex, get.Mutex)
+	}
+
+	if io := byID[64]; io.State != "IO wait" || io.Wait != 4*time.Minute || io.Mutex != "" {
+		t.Errorf("IO goroutine = %+v", io)
+	}
+}
+
+func TestGoroutineDumpRWMutexWithoutAddress(t *testing.T) {
+	// The default GOTRACEBACK hides runtime.semacquire1 and prints guesses for the arguments
+	text := "goroutine 7 [sync.RWMutex.RLock, 2 minutes]:\n" +
+		"sync.runtime_SemacquireRWMutexR(0x40?, 0x0?, 0x40?)\n\t/usr/local/go/src/runtime/sema.go:100 +0x25\n" +
+		"sync.(*RWMutex).RLock(...)\n\t/usr/local/go/src/sync/rwmutex.go:74\n" +
+		"main.(*Cache).Get(0xc000014000, {0x4da002, 0x1})\n\t/srv/app/cache.go:17 +0x55\n"
+
+	dump := GoroutineDump(text)
+	if len(dump.Goroutines) != 1 || dump.Goroutines[0].Mutex != "" {
+		t.Errorf("dump = %+v, want one goroutine with no mutex address", dump)
+	}
+}
+
+func TestGoroutineDumpDeadlock(t *testing.T) {
+	text := "fatal error: all goroutines are asleep - deadlock!\n\n" +
+		"goroutine 1 gp=0xc000002380 m=nil [chan receive]:\n" +
+		"main.main()\n\t/tmp/x/main.go:5 +0x25\nexit status 2\n"
+
+	dump := GoroutineDump(text)
+	if !dump.Deadlock || len(dump.Goroutines) != 1 || dump.Goroutines[0].State != "chan receive" {
+		t.Errorf("dump = %+v, want one goroutine and the deadlock", dump)
+	}
+}
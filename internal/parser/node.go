@@ -0,0 +1,154 @@
+package parser
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/arnislvdev/go-guru-ui/internal/domain"
+)
+
+var (
+	// nodeError matches the line naming the error, e.g. "TypeError: x is not a function"
+	// or "Error [ERR_MODULE_NOT_FOUND]: Cannot find package 'x'"
+	nodeError = regexp.MustCompile(`^(?:Uncaught )?([A-Z]\w*(?:Error|Exception)|Error)(?: \[(\w+)\])?: (.*)$`)
+
+	// nodeFrame matches "at load (/app/src/config.js:12:5)" and "at /app/src/index.js:3:1"
+	nodeFrame = regexp.MustCompile(`^at (?:async )?(?:(.+?) \()?(.+?):(\d+):(\d+)\)?$`)
+
+	// nodeThrowSite matches the "/app/src/index.js:3" line Node prints above the code that threw
+	nodeThrowSite = regexp.MustCompile(`^(.+\.[cm]?[jt]sx?):(\d+)$`)
+
+	// nodeSystemError matches messages of failed system calls, as in "ENOENT: no such file or directory"
+	nodeSystemError = regexp.MustCompile(`^E[A-Z]+: `)
+)
+
+// NodeError parses an uncaught exception from Node.js: the error line and the
+// "at ..." lines of its stack. File, Line and Column point at the innermost
+// call in the program's own code rather than in node_modules or Node itself;
+// when there is none, as for a SyntaxError, they come from the place Node
+// shows above the error. It reports false when text holds no Node.js error
+// with a stack.
+func NodeError(text string) (*domain.Error, bool) {
+	lines := strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n")
+
+	var site struct {
+		file         string
+		line, column int
+	}
+	for i, raw := range lines {
+		line := strings.TrimSpace(raw)
+		if match := nodeThrowSite.FindStringSubmatch(line); match != nil && site.file == "" {
+			site.file = strings.TrimPrefix(match[1], "file://")
+			site.line, _ = strconv.Atoi(match[2])
+			// The code and a ^ under the column that threw follow
+			if i+2 < len(lines) && strings.Trim(lines[i+2], "^ ") == "" {
+				site.column = strings.IndexByte(lines[i+2], '^') + 1
+			}
+			continue
+		}
+
+		match := nodeError.FindStringSubmatch(line)
+		if match == nil || strings.HasPrefix(raw, " ") {
+			continue
+		}
+		stack, columns := readNodeStack(lines[i+1:])
+		if len(stack) == 0 {
+			continue
+		}
+
+		err := &domain.Error{
+			Message:   line,
+			Type:      classifyNodeError(match[1], match[2], match[3]),
+			Code:      match[2],
+			Severity:  domain.SeverityError,
+			Exception: match[1],
+			Stack:     stack,
+		}
+		for n := range stack {
+			if stack[n].User {
+				err.File, err.Line, err.Column = stack[n].File, stack[n].Line, columns[n]
+				break
+			}
+		}
+		if err.File == "" && site.file != "" {
+			err.File, err.Line, err.Column = site.file, site.line, site.column
+		}
+		err.Language = scriptLanguage(err.File)
+		return err, true
+	}
+	return nil, false
+}
+
+// readNodeStack reads the "at ..." lines at the start of lines, innermost
+// call first, with the column of each call
+func readNodeStack(lines []string) ([]domain.Frame, []int) {
+	var stack []domain.Frame
+	var columns []int
+	for _, raw := range lines {
+		// Node prints the error's own properties after the last call, opening with " {"
+		line := strings.TrimSuffix(strings.TrimSpace(raw), " {")
+		if !strings.HasPrefix(line, "at ") {
+			break
+		}
+		match := nodeFrame.FindStringSubmatch(line)
+		if match == nil || len(stack) == maxFrames {
+			// Calls without a place, like "at new Promise (<anonymous>)"
+			continue
+		}
+
+		function := match[1]
+		if function == "" {
+			function = "<anonymous>"
+		}
+		file := strings.TrimPrefix(match[2], "file://")
+		lineNo, _ := strconv.Atoi(match[3])
+		column, _ := strconv.Atoi(match[4])
+		stack = append(stack, domain.Frame{
+			Function: function,
+			File:     file,
+			Line:     lineNo,
+			User:     isNodeUserCode(file),
+		})
+		columns = append(columns, column)
+	}
+	return stack, columns
+}
+
+// isNodeUserCode reports whether a file is the program's own rather than
+// Node's or an installed package's
+func isNodeUserCode(file string) bool {
+	return !strings.HasPrefix(file, "node:") &&
+		!strings.HasPrefix(file, "internal/") &&
+		!strings.Contains(file, "node_modules") &&
+		file != "<anonymous>"
+}
+
+// classifyNodeError picks the error type for an error, looking at the message
+// where the class alone is too broad
+func classifyNodeError(name, code, message string) string {
+	switch {
+	case code == "ERR_MODULE_NOT_FOUND" || code == "MODULE_NOT_FOUND" ||
+		strings.HasPrefix(message, "Cannot find module") || strings.HasPrefix(message, "Cannot find package"):
+		return domain.ErrorTypeImport
+	case strings.Contains(message, "of undefined") || strings.Contains(message, "of null") ||
+		strings.HasPrefix(message, "undefined is not") || strings.HasPrefix(message, "null is not"):
+		return domain.ErrorTypeNullReference
+	case strings.Contains(message, "Maximum call stack size exceeded"):
+		return domain.ErrorTypeResource
+	case nodeSystemError.MatchString(message):
+		return domain.ErrorTypeIO
+	}
+
+	switch name {
+	case "ReferenceError":
+		return domain.ErrorTypeUndefinedSymbol
+	case "SyntaxError":
+		return domain.ErrorTypeSyntax
+	case "TypeError":
+		return domain.ErrorTypeTypeMismatch
+	case "RangeError":
+		return domain.ErrorTypeValue
+	}
+	return domain.ErrorTypeUnknown
+}
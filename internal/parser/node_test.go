@@ -0,0 +1,72 @@
+package parser
+
+import (
+	"testing"
+
+	"github.com/arnislvdev/go-guru-ui/internal/domain"
+)
+
+func TestNodeErrorPointsAtUserCode(t *testing.T) {
+	err, ok := NodeError(readTestdata(t, "node_type_error.txt"))
+	if !ok {
+		t.Fatal("NodeError found no error")
+	}
+
+	if err.Message != "TypeError: Cannot read properties of undefined (reading 'id')" || err.Exception != "TypeError" {
+		t.Errorf("message = %q, exception = %q", err.Message, err.Exception)
+	}
+	if err.Type != domain.ErrorTypeNullReference || err.Language != domain.LanguageJS {
+		t.Errorf("type = %q, language = %q", err.Type, err.Language)
+	}
+	if err.File != "/home/dev/api/src/routes/users.js" || err.Line != 18 || err.Column != 32 {
+		t.Errorf("location = %s:%d:%d, want users.js:18:32", err.File, err.Line, err.Column)
+	}
+
+	want := []domain.Frame{
+		{Function: "getUser", File: "/home/dev/api/src/routes/users.js", Line: 18, User: true},
+		{Function: "Layer.handle [as handle_request]", File: "/home/dev/api/node_modules/express/lib/router/layer.js", Line: 95},
+		{Function: "next", File: "/home/dev/api/node_modules/express/lib/router/route.js", Line: 149},
+		{Function: "process.processTicksAndRejections", File: "node:internal/process/task_queues", Line: 95},
+	}
+	if len(err.Stack) != len(want) {
+		t.Fatalf("stack = %+v, want %+v", err.Stack, want)
+	}
+	for i := range want {
+		if err.Stack[i] != want[i] {
+			t.Errorf("frame %d = %+v, want %+v", i, err.Stack[i], want[i])
+		}
+	}
+}
+
+func TestNodeErrorModuleNotFound(t *testing.T) {
+	err, ok := NodeError(readTestdata(t, "node_module.txt"))
+	if !ok {
+		t.Fatal("NodeError found no error")
+	}
+
+	if err.Code != "ERR_MODULE_NOT_FOUND" || err.Type != domain.ErrorTypeImport || err.Exception != "Error" {
+		t.Errorf("code = %q, type = %q, exception = %q", err.Code, err.Type, err.Exception)
+	}
+	if len(err.Stack) != 3 || err.File != "" {
+		t.Errorf("stack = %+v, file = %q; want three calls inside Node and no user file", err.Stack, err.File)
+	}
+}
+
+func TestNodeErrorNeedsAStack(t *testing.T) {
+	for _, text := range []string{
+		"TypeError: Cannot read properties of undefined (reading 'id')",
+		readTestdata(t, "py_chained.txt"),
+		readTestdata(t, "nil_map.txt"),
+	} {
+		if err, ok := NodeError(text); ok {
+			t.Errorf("found %+v", err)
+		}
+	}
+}
+
+func TestParseNodeBeforePython(t *testing.T) {
+	err, ok := Parse(readTestdata(t, "node_type_error.txt"))
+	if !ok || err.Language != domain.LanguageJS {
+		t.Errorf("ok = %v, err = %+v; want the Node.js error", ok, err)
+	}
+}
@@ -0,0 +1,27 @@
+package parser
+
+import "github.com/arnislvdev/go-guru-ui/internal/domain"
+
+// Parse reads a single error that comes with its stack: a Go panic or fatal
+// runtime error, an uncaught Node.js exception, or a Python traceback. It
+// reports false for anything else.
+func Parse(text string) (*domain.Error, bool) {
+	if err, ok := GoPanic(text); ok {
+		return err, true
+	}
+	if err, ok := NodeError(text); ok {
+		return err, true
+	}
+	return PythonTraceback(text)
+}
+
+// Diagnostics reads build and lint output that may hold many errors: from go
+// build and go vet, tsc, or ESLint's default formatter. Output that mixes
+// them, as from a script running several tools, gives the errors of each.
+func Diagnostics(output string) BuildOutput {
+	result := GoBuild(output)
+	for _, other := range []BuildOutput{TypeScript(output), ESLint(output)} {
+		result.Errors = append(result.Errors, other.Errors...)
+	}
+	return result
+}
@@ -0,0 +1,239 @@
+package parser
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/arnislvdev/go-guru-ui/internal/domain"
+)
+
+const pythonTracebackHeader = "Traceback (most recent call last):"
+
+var (
+	// pythonFrame matches `File "/app/x.py", line 12, in load`; SyntaxErrors leave out the function
+	pythonFrame = regexp.MustCompile(`^File "(.+)", line (\d+)(?:, in (.+))?$`)
+
+	// pythonException matches the last line of a traceback, e.g. "KeyError: 'id'" or "json.decoder.JSONDecodeError: ..."
+	pythonException = regexp.MustCompile(`^([A-Za-z_][\w.]*)(?:: ?(.*))?$`)
+
+	// pythonExceptionName matches class names that are exceptions by convention
+	pythonExceptionName = regexp.MustCompile(`(?:Error|Exception|Warning|Interrupt|Exit)$`)
+
+	// pythonStdlib matches files of the standard library or installed packages
+	pythonStdlib = regexp.MustCompile(`/lib/python[0-9.]*/|\\Lib\\|site-packages|dist-packages`)
+)
+
+// pythonExceptions maps built-in exception classes to error types
+var pythonExceptions = map[string]string{
+	"SyntaxError":            domain.ErrorTypeSyntax,
+	"IndentationError":       domain.ErrorTypeSyntax,
+	"TabError":               domain.ErrorTypeSyntax,
+	"ImportError":            domain.ErrorTypeImport,
+	"ModuleNotFoundError":    domain.ErrorTypeImport,
+	"NameError":              domain.ErrorTypeUndefinedSymbol,
+	"UnboundLocalError":      domain.ErrorTypeUndefinedSymbol,
+	"AttributeError":         domain.ErrorTypeUndefinedSymbol,
+	"TypeError":              domain.ErrorTypeTypeMismatch,
+	"KeyError":               domain.ErrorTypeLookup,
+	"IndexError":             domain.ErrorTypeLookup,
+	"ValueError":             domain.ErrorTypeValue,
+	"UnicodeDecodeError":     domain.ErrorTypeValue,
+	"UnicodeEncodeError":     domain.ErrorTypeValue,
+	"JSONDecodeError":        domain.ErrorTypeValue,
+	"AssertionError":         domain.ErrorTypeValue,
+	"ZeroDivisionError":      domain.ErrorTypeArithmetic,
+	"OverflowError":          domain.ErrorTypeArithmetic,
+	"FloatingPointError":     domain.ErrorTypeArithmetic,
+	"OSError":                domain.ErrorTypeIO,
+	"IOError":                domain.ErrorTypeIO,
+	"FileNotFoundError":      domain.ErrorTypeIO,
+	"FileExistsError":        domain.ErrorTypeIO,
+	"PermissionError":        domain.ErrorTypeIO,
+	"IsADirectoryError":      domain.ErrorTypeIO,
+	"NotADirectoryError":     domain.ErrorTypeIO,
+	"ConnectionError":        domain.ErrorTypeIO,
+	"ConnectionRefusedError": domain.ErrorTypeIO,
+	"ConnectionResetError":   domain.ErrorTypeIO,
+	"BrokenPipeError":        domain.ErrorTypeIO,
+	"TimeoutError":           domain.ErrorTypeIO,
+	"RecursionError":         domain.ErrorTypeResource,
+	"MemoryError":            domain.ErrorTypeResource,
+	"RuntimeError":           domain.ErrorTypeUnknown,
+	"NotImplementedError":    domain.ErrorTypeUnknown,
+	"StopIteration":          domain.ErrorTypeUnknown,
+	"KeyboardInterrupt":      domain.ErrorTypeUnknown,
+	"Exception":              domain.ErrorTypeUnknown,
+	"ExceptionGroup":         domain.ErrorTypeUnknown,
+	"BaseExceptionGroup":     domain.ErrorTypeUnknown,
+	"ChildProcessError":      domain.ErrorTypeIO,
+	"ProcessLookupError":     domain.ErrorTypeIO,
+	"InterruptedError":       domain.ErrorTypeIO,
+	"BlockingIOError":        domain.ErrorTypeIO,
+	"ConnectionAbortedError": domain.ErrorTypeIO,
+	"UnicodeTranslateError":  domain.ErrorTypeValue,
+	"LookupError":            domain.ErrorTypeLookup,
+	"ArithmeticError":        domain.ErrorTypeArithmetic,
+	"EnvironmentError":       domain.ErrorTypeIO,
+	"ReferenceError":         domain.ErrorTypeNullReference,
+	"SystemError":            domain.ErrorTypeUnknown,
+	"UnicodeError":           domain.ErrorTypeValue,
+}
+
+// pythonRaised is one exception of a traceback
+type pythonRaised struct {
+	line   string // "KeyError: 'id'"
+	name   string
+	frames []domain.Frame // outermost first, as Python prints them
+	column int            // where the last ^ marker points
+}
+
+// PythonTraceback parses a Python traceback, including chained exceptions
+// ("During handling of the above exception ...") and the caret under a
+// SyntaxError. The error is the exception raised last; the ones before it
+// are its Causes. File and Line point at the innermost call in the program's
+// own code rather than the standard library or an installed package. A bare
+// line naming a built-in exception, such as "NameError: name 'x' is not
+// defined", is read as a traceback without calls, unless JavaScript has an
+// exception of that name too. It reports false when text holds no Python
+// exception.
+func PythonTraceback(text string) (*domain.Error, bool) {
+	lines := strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n")
+
+	var raised []pythonRaised
+	var current pythonRaised
+	inTraceback := false
+	lastCode := ""
+
+	for _, raw := range lines {
+		line := strings.TrimSpace(raw)
+		switch {
+		case line == "":
+			continue
+		case line == pythonTracebackHeader:
+			current, inTraceback = pythonRaised{}, true
+			continue
+		}
+
+		if match := pythonFrame.FindStringSubmatch(line); match != nil {
+			lineNo, _ := strconv.Atoi(match[2])
+			function := match[3]
+			if function == "" {
+				function = "<module>"
+			}
+			current.frames = append(current.frames, domain.Frame{
+				Function: function,
+				File:     match[1],
+				Line:     lineNo,
+				User:     isPythonUserCode(match[1]),
+			})
+			inTraceback, lastCode = true, ""
+			continue
+		}
+
+		// Source lines and the ^ or ~ markers under them are indented
+		if strings.HasPrefix(raw, " ") || strings.HasPrefix(raw, "\t") {
+			if strings.Trim(line, "^~ ") == "" && lastCode != "" {
+				current.column = caretColumn(lastCode, raw)
+			} else {
+				lastCode = raw
+			}
+			continue
+		}
+
+		match := pythonException.FindStringSubmatch(line)
+		if match == nil || !isPythonException(match[1], inTraceback) {
+			continue
+		}
+		current.line, current.name = line, match[1]
+		raised = append(raised, current)
+		current, inTraceback, lastCode = pythonRaised{}, false, ""
+	}
+
+	if len(raised) == 0 {
+		return nil, false
+	}
+	last := raised[len(raised)-1]
+
+	err := &domain.Error{
+		Message:   last.line,
+		Type:      classifyPythonException(last.name, last.line),
+		Severity:  domain.SeverityError,
+		Language:  domain.LanguagePython,
+		Exception: last.name,
+	}
+	for _, cause := range raised[:len(raised)-1] {
+		err.Causes = append(err.Causes, cause.line)
+	}
+
+	// Innermost call first, like Go stacks
+	for i := len(last.frames) - 1; i >= 0 && len(err.Stack) < maxFrames; i-- {
+		err.Stack = append(err.Stack, last.frames[i])
+	}
+	if frame := FirstUserFrame(err.Stack); frame != nil {
+		err.File, err.Line = frame.File, frame.Line
+	}
+	// Python 3.11 marks the failing expression in every call; only a SyntaxError's caret is a column
+	if pythonExceptions[shortName(last.name)] == domain.ErrorTypeSyntax {
+		err.Column = last.column
+	}
+	return err, true
+}
+
+// sharedWithJavaScript lists built-in exceptions JavaScript throws under the same name
+var sharedWithJavaScript = map[string]bool{
+	"TypeError":      true,
+	"SyntaxError":    true,
+	"ReferenceError": true,
+}
+
+// isPythonException reports whether name is an exception class. Outside a
+// traceback only built-in exceptions that JavaScript doesn't share count, so
+// neither a stray "Note: ..." nor a bare "TypeError: ..." from a browser is one.
+func isPythonException(name string, inTraceback bool) bool {
+	short := shortName(name)
+	_, builtin := pythonExceptions[short]
+	if inTraceback {
+		return builtin || pythonExceptionName.MatchString(name)
+	}
+	return builtin && !sharedWithJavaScript[short]
+}
+
+// classifyPythonException picks the error type for an exception, looking at
+// the message where the class alone is too broad
+func classifyPythonException(name, line string) string {
+	message := strings.ToLower(line)
+	switch {
+	case strings.Contains(message, "'nonetype' object"):
+		return domain.ErrorTypeNullReference
+	case strings.Contains(message, "positional argument") || strings.Contains(message, "unexpected keyword argument"):
+		return domain.ErrorTypeArgumentCount
+	}
+
+	if errorType, ok := pythonExceptions[shortName(name)]; ok {
+		return errorType
+	}
+	return domain.ErrorTypeUnknown
+}
+
+// shortName drops the module from a class name, as in json.decoder.JSONDecodeError
+func shortName(name string) string {
+	return name[strings.LastIndexByte(name, '.')+1:]
+}
+
+// caretColumn returns the 1-based column a SyntaxError's caret points at
+// within the source line printed above it
+func caretColumn(code, caret string) int {
+	indent := len(code) - len(strings.TrimLeft(code, " \t"))
+	at := strings.IndexAny(caret, "^~")
+	if at < indent {
+		return 0
+	}
+	return at - indent + 1
+}
+
+// isPythonUserCode reports whether a file is the program's own rather than
+// the standard library, an installed package or Python's frozen modules
+func isPythonUserCode(file string) bool {
+	return !strings.HasPrefix(file, "<frozen ") && !pythonStdlib.MatchString(file)
+}
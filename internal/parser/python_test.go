@@ -0,0 +1,148 @@
+package parser
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/arnislvdev/go-guru-ui/internal/domain"
+)
+
+func TestPythonTracebackChained(t *testing.T) {
+	err, ok := PythonTraceback(readTestdata(t, "py_chained.txt"))
+	if !ok {
+		t.Fatal("PythonTraceback found no exception")
+	}
+
+	if err.Message != "json.decoder.JSONDecodeError: Expecting value: line 1 column 1 (char 0)" {
+		t.Errorf("message = %q", err.Message)
+	}
+	if err.Exception != "json.decoder.JSONDecodeError" || err.Type != domain.ErrorTypeValue || err.Language != domain.LanguagePython {
+		t.Errorf("exception = %q, type = %q, language = %q", err.Exception, err.Type, err.Language)
+	}
+	if want := []string{"KeyError: 'database'"}; !reflect.DeepEqual(err.Causes, want) {
+		t.Errorf("causes = %q, want %q", err.Causes, want)
+	}
+
+	want := []domain.Frame{
+		{Function: "loads", File: "/usr/lib/python3.11/json/__init__.py", Line: 346},
+		{Function: "load", File: "/home/dev/app/config.py", Line: 16, User: true},
+		{Function: "main", File: "/home/dev/app/main.py", Line: 25, User: true},
+		{Function: "<module>", File: "/home/dev/app/main.py", Line: 30, User: true},
+	}
+	if !reflect.DeepEqual(err.Stack, want) {
+		t.Errorf("stack = %+v, want %+v", err.Stack, want)
+	}
+	if err.File != "/home/dev/app/config.py" || err.Line != 16 || err.Column != 0 {
+		t.Errorf("location = %s:%d:%d, want the load call", err.File, err.Line, err.Column)
+	}
+}
+
+func TestPythonTracebackSyntaxError(t *testing.T) {
+	err, ok := PythonTraceback(readTestdata(t, "py_syntax.txt"))
+	if !ok {
+		t.Fatal("PythonTraceback found no exception")
+	}
+
+	if err.Exception != "SyntaxError" || err.Type != domain.ErrorTypeSyntax {
+		t.Errorf("exception = %q, type = %q", err.Exception, err.Type)
+	}
+	if err.File != "/home/dev/app/main.py" || err.Line != 7 || err.Column != 4 {
+		t.Errorf("location = %s:%d:%d, want main.py:7:4", err.File, err.Line, err.Column)
+	}
+}
+
+func TestPythonTracebackSkipsPackages(t *testing.T) {
+	err, ok := PythonTraceback(readTestdata(t, "py_none.txt"))
+	if !ok {
+		t.Fatal("PythonTraceback found no exception")
+	}
+
+	if err.Type != domain.ErrorTypeNullReference {
+		t.Errorf("type = %q, want %q", err.Type, domain.ErrorTypeNullReference)
+	}
+	if len(err.Stack) != 2 || err.Stack[0].User {
+		t.Errorf("stack = %+v, want the package call first and not marked as user code", err.Stack)
+	}
+	if err.File != "/home/dev/app/report.py" || err.Line != 9 {
+		t.Errorf("location = %s:%d, want report.py:9", err.File, err.Line)
+	}
+}
+
+func TestPythonTracebackClassifies(t *testing.T) {
+	tests := []struct {
+		text     string
+		wantType string
+	}{
+		{"NameError: name 'totl' is not defined", domain.ErrorTypeUndefinedSymbol},
+		{"ModuleNotFoundError: No module named 'requests'", domain.ErrorTypeImport},
+		{"IndexError: list index out of range", domain.ErrorTypeLookup},
+		{"ZeroDivisionError: division by zero", domain.ErrorTypeArithmetic},
+		{"FileNotFoundError: [Errno 2] No such file or directory: 'data.csv'", domain.ErrorTypeIO},
+		{"RecursionError: maximum recursion depth exceeded", domain.ErrorTypeResource},
+	}
+
+	for _, tt := range tests {
+		err, ok := PythonTraceback(tt.text)
+		if !ok {
+			t.Errorf("%q: no exception found", tt.text)
+			continue
+		}
+		if err.Type != tt.wantType || err.Message != tt.text || len(err.Stack) != 0 {
+			t.Errorf("%q: type = %q, message = %q, stack = %v", tt.text, err.Type, err.Message, err.Stack)
+		}
+	}
+}
+
+func TestPythonTracebackClassifiesTypeErrors(t *testing.T) {
+	// JavaScript has a TypeError too, so these need the traceback around them
+	tests := []struct {
+		line     string
+		wantType string
+	}{
+		{"TypeError: add() takes 2 positional arguments but 3 were given", domain.ErrorTypeArgumentCount},
+		{"TypeError: can only concatenate str (not \"int\") to str", domain.ErrorTypeTypeMismatch},
+	}
+
+	for _, tt := range tests {
+		text := "Traceback (most recent call last):\n  File \"/app/calc.py\", line 4, in <module>\n    add(1, 2, 3)\n" + tt.line
+		err, ok := PythonTraceback(text)
+		if !ok {
+			t.Errorf("%q: no exception found", tt.line)
+			continue
+		}
+		if err.Type != tt.wantType || err.Message != tt.line || err.File != "/app/calc.py" || err.Line != 4 {
+			t.Errorf("%q: type = %q, message = %q, location = %s:%d", tt.line, err.Type, err.Message, err.File, err.Line)
+		}
+	}
+}
+
+func TestPythonTracebackIgnoresOtherText(t *testing.T) {
+	for _, text := range []string{
+		"undefined: fmt",
+		"./main.go:3:2: \"os\" imported and not used",
+		"MyError: not a built-in exception outside a traceback",
+		"ReferenceError: foo is not defined",
+		"TypeError: Cannot read properties of undefined (reading 'x')",
+		"SyntaxError: Unexpected token '}'",
+		"",
+	} {
+		if err, ok := PythonTraceback(text); ok {
+			t.Errorf("%q: found %+v", text, err)
+		}
+	}
+}
+
+func TestParse(t *testing.T) {
+	if err, ok := Parse(readTestdata(t, "nil_map.txt")); !ok || err.Language != domain.LanguageGo {
+		t.Errorf("Go panic: ok = %v, err = %+v", ok, err)
+	}
+	if err, ok := Parse(readTestdata(t, "py_syntax.txt")); !ok || err.Language != domain.LanguagePython {
+		t.Errorf("Python traceback: ok = %v, err = %+v", ok, err)
+	}
+	if err, ok := Parse("TypeError: Cannot read properties of undefined (reading 'x')"); ok {
+		t.Errorf("bare JavaScript error parsed as %s: %+v", err.Language, err)
+	}
+	if _, ok := Parse("cannot use x (type int) as type string"); ok {
+		t.Error("plain compiler error parsed as a stack")
+	}
+}
@@ -0,0 +1,143 @@
+package parser
+
+import (
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/arnislvdev/go-guru-ui/internal/domain"
+)
+
+var (
+	// tscPosition matches "src/app.ts(12,5): error TS2345: message", tsc's plain output
+	tscPosition = regexp.MustCompile(`^(.+?)\((\d+),(\d+)\): (error|warning|message) TS(\d+): (.*)$`)
+
+	// tscPretty matches "src/app.ts:12:5 - error TS2345: message", what tsc prints to a terminal
+	tscPretty = regexp.MustCompile(`^(.+?):(\d+):(\d+) - (error|warning|message) TS(\d+): (.*)$`)
+
+	// tscGlobal matches diagnostics about the project rather than a file, as in "error TS5023: Unknown compiler option"
+	tscGlobal = regexp.MustCompile(`^(error|warning|message) TS(\d+): (.*)$`)
+
+	// ansiColor matches the color codes in tsc --pretty output
+	ansiColor = regexp.MustCompile("\x1b\\[[0-9;]*m")
+)
+
+// tsCodes maps the TypeScript diagnostics people hit most to error types
+var tsCodes = map[int]string{
+	2304:  domain.ErrorTypeUndefinedSymbol, // Cannot find name 'x'
+	2552:  domain.ErrorTypeUndefinedSymbol, // Cannot find name 'x'. Did you mean 'y'?
+	2339:  domain.ErrorTypeUndefinedSymbol, // Property 'x' does not exist on type 'T'
+	2551:  domain.ErrorTypeUndefinedSymbol, // Property 'x' does not exist on type 'T'. Did you mean 'y'?
+	2305:  domain.ErrorTypeImport,          // Module 'm' has no exported member 'x'
+	2307:  domain.ErrorTypeImport,          // Cannot find module 'm' or its type declarations
+	2614:  domain.ErrorTypeImport,          // Module 'm' has no exported member 'x'. Did you mean to use 'import x from'?
+	7016:  domain.ErrorTypeImport,          // Could not find a declaration file for module 'm'
+	2322:  domain.ErrorTypeTypeMismatch,    // Type 'A' is not assignable to type 'B'
+	2345:  domain.ErrorTypeTypeMismatch,    // Argument of type 'A' is not assignable to parameter of type 'B'
+	2739:  domain.ErrorTypeTypeMismatch,    // Type 'A' is missing the following properties from type 'B'
+	2741:  domain.ErrorTypeTypeMismatch,    // Property 'x' is missing in type 'A' but required in type 'B'
+	2769:  domain.ErrorTypeTypeMismatch,    // No overload matches this call
+	7006:  domain.ErrorTypeTypeMismatch,    // Parameter 'x' implicitly has an 'any' type
+	2554:  domain.ErrorTypeArgumentCount,   // Expected 2 arguments, but got 1
+	2555:  domain.ErrorTypeArgumentCount,   // Expected at least 2 arguments, but got 1
+	2531:  domain.ErrorTypeNullReference,   // Object is possibly 'null'
+	2532:  domain.ErrorTypeNullReference,   // Object is possibly 'undefined'
+	2533:  domain.ErrorTypeNullReference,   // Object is possibly 'null' or 'undefined'
+	18047: domain.ErrorTypeNullReference,   // 'x' is possibly 'null'
+	18048: domain.ErrorTypeNullReference,   // 'x' is possibly 'undefined'
+	18049: domain.ErrorTypeNullReference,   // 'x' is possibly 'null' or 'undefined'
+	6133:  domain.ErrorTypeUnusedVariable,  // 'x' is declared but its value is never read
+	6196:  domain.ErrorTypeUnusedVariable,  // 'T' is declared but never used
+	6192:  domain.ErrorTypeUnusedImport,    // All imports in import declaration are unused
+	2355:  domain.ErrorTypeMissingReturn,   // A function whose declared type is neither 'void' nor 'any' must return a value
+	2366:  domain.ErrorTypeMissingReturn,   // Function lacks ending return statement
+	7030:  domain.ErrorTypeMissingReturn,   // Not all code paths return a value
+}
+
+// TypeScript parses the output of tsc into one error per diagnostic, in
+// either its plain or its --pretty form. Indented lines right after a
+// diagnostic (the "Type 'A' is not assignable ..." chain) are added to its
+// message; the code excerpts --pretty prints after a blank line are not.
+func TypeScript(output string) BuildOutput {
+	var result BuildOutput
+	var last *domain.Error
+
+	for _, raw := range strings.Split(strings.ReplaceAll(output, "\r\n", "\n"), "\n") {
+		raw = ansiColor.ReplaceAllString(raw, "")
+		line := strings.TrimSpace(raw)
+		if line == "" {
+			last = nil
+			continue
+		}
+		if strings.HasPrefix(raw, " ") && last != nil {
+			last.Message += "\n" + line
+			continue
+		}
+
+		last = nil
+		match := tscPosition.FindStringSubmatch(line)
+		if match == nil {
+			match = tscPretty.FindStringSubmatch(line)
+		}
+		if match == nil {
+			global := tscGlobal.FindStringSubmatch(line)
+			if global == nil {
+				continue
+			}
+			match = []string{global[0], "", "0", "0", global[1], global[2], global[3]}
+		}
+
+		lineNo, _ := strconv.Atoi(match[2])
+		column, _ := strconv.Atoi(match[3])
+		code, _ := strconv.Atoi(match[5])
+		last = &domain.Error{
+			Message:  match[6],
+			Type:     classifyTSCode(code),
+			File:     match[1],
+			Line:     lineNo,
+			Column:   column,
+			Code:     "TS" + match[5],
+			Severity: tscSeverity(match[4]),
+			Language: domain.LanguageTS,
+		}
+		result.Errors = append(result.Errors, last)
+	}
+
+	result.Errors = dedupe(result.Errors)
+	return result
+}
+
+// classifyTSCode picks the error type for a TypeScript diagnostic code
+func classifyTSCode(code int) string {
+	if errorType, ok := tsCodes[code]; ok {
+		return errorType
+	}
+	// TS1xxx are all errors in reading the source
+	if code >= 1000 && code < 2000 {
+		return domain.ErrorTypeSyntax
+	}
+	return domain.ErrorTypeUnknown
+}
+
+// tscSeverity turns tsc's category into a severity
+func tscSeverity(category string) string {
+	switch category {
+	case "warning":
+		return domain.SeverityWarning
+	case "message":
+		return domain.SeverityInfo
+	default:
+		return domain.SeverityError
+	}
+}
+
+// scriptLanguage tells TypeScript files from JavaScript ones by their extension
+func scriptLanguage(file string) string {
+	switch filepath.Ext(file) {
+	case ".ts", ".tsx", ".mts", ".cts":
+		return domain.LanguageTS
+	default:
+		return domain.LanguageJS
+	}
+}
@@ -0,0 +1,63 @@
+package parser
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/arnislvdev/go-guru-ui/internal/domain"
+)
+
+func TestTypeScript(t *testing.T) {
+	output := TypeScript(readTestdata(t, "tsc.txt"))
+
+	type diagnostic struct {
+		file         string
+		line, column int
+		code, typ    string
+	}
+	var got []diagnostic
+	for _, err := range output.Errors {
+		got = append(got, diagnostic{err.File, err.Line, err.Column, err.Code, err.Type})
+		if err.Language != domain.LanguageTS || err.Severity != domain.SeverityError {
+			t.Errorf("%s: language = %q, severity = %q", err.Code, err.Language, err.Severity)
+		}
+	}
+	want := []diagnostic{
+		{"src/api/client.ts", 12, 5, "TS2345", domain.ErrorTypeTypeMismatch},
+		{"src/api/client.ts", 20, 18, "TS2532", domain.ErrorTypeNullReference},
+		{"src/components/Card.tsx", 3, 10, "TS2305", domain.ErrorTypeImport},
+		{"src/components/Card.tsx", 14, 7, "TS2322", domain.ErrorTypeTypeMismatch},
+		{"src/index.ts", 8, 1, "TS1005", domain.ErrorTypeSyntax},
+		{"", 0, 0, "TS5023", domain.ErrorTypeUnknown},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("diagnostics = %+v\nwant %+v", got, want)
+	}
+
+	wantMessage := "Type '{ title: string; }' is not assignable to type 'Props'.\n" +
+		"Property 'id' is missing in type '{ title: string; }' but required in type 'Props'."
+	if len(output.Errors) > 3 && output.Errors[3].Message != wantMessage {
+		t.Errorf("message = %q, want the note added", output.Errors[3].Message)
+	}
+}
+
+func TestTypeScriptPretty(t *testing.T) {
+	output := TypeScript(readTestdata(t, "tsc_pretty.txt"))
+
+	if len(output.Errors) != 2 {
+		t.Fatalf("errors = %+v, want 2", output.Errors)
+	}
+	first, second := output.Errors[0], output.Errors[1]
+	if first.Message != "Cannot find name 'fetchUser'." || first.Type != domain.ErrorTypeUndefinedSymbol || first.Line != 4 || first.Column != 7 {
+		t.Errorf("first = %+v", first)
+	}
+	if second.Code != "TS2554" || second.Type != domain.ErrorTypeArgumentCount || second.Line != 9 {
+		t.Errorf("second = %+v", second)
+	}
+}
+
+func TestTypeScriptIgnoresGoOutput(t *testing.T) {
+	if output := TypeScript(readTestdata(t, "nil_map.txt")); len(output.Errors) != 0 {
+		t.Errorf("errors = %+v, want none", output.Errors)
+	}
+}
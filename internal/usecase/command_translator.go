@@ -1,27 +1,47 @@
 package usecase
 
 import (
+	"context"
+
+	"github.com/arnislvdev/go-guru-ui/internal/domain"
 	"github.com/arnislvdev/go-guru-ui/internal/infrastructure/ai"
+	"github.com/arnislvdev/go-guru-ui/pkg/redact"
 )
 
 // CommandTranslator handles the business logic for translating natural language to CLI commands
 type CommandTranslator struct {
 	aiClient ai.Client
+	redactor *redact.Redactor
+
+	// OnRedact, if set, is told how many values were hidden from the AI
+	OnRedact func(count int)
 }
 
-// NewCommandTranslator creates a new CommandTranslator instance
-func NewCommandTranslator() *CommandTranslator {
+// NewCommandTranslator creates a new CommandTranslator that asks the given AI client
+func NewCommandTranslator(client ai.Client, opts ...Option) *CommandTranslator {
+	o := applyOptions(opts)
 	return &CommandTranslator{
-		aiClient: ai.NewOpenAIClient(),
+		aiClient: client,
+		redactor: o.redactor,
 	}
 }
 
 // Translate converts a natural language query to a CLI command
-func (c *CommandTranslator) Translate(query, contextInfo, mode string) (string, string, error) {
+func (c *CommandTranslator) Translate(ctx context.Context, query, contextInfo, mode string) (*domain.Command, error) {
+	session := c.redactor.Session()
+	redactedQuery, redactedContext := session.Redact(query), session.Redact(contextInfo)
+	if session != nil && c.OnRedact != nil {
+		c.OnRedact(session.Count())
+	}
+
 	// Use AI to translate the query
-	command, err := c.aiClient.TranslateQuery(query, contextInfo)
+	command, err := c.aiClient.TranslateQuery(ctx, redactedQuery, redactedContext)
 	if err != nil {
-		return "", "", err
+		return nil, err
+	}
+	if session.Count() > 0 {
+		restoreCommand(session, command)
+		command.Context = contextInfo
 	}
 
 	// Format the output based on mode
@@ -29,7 +49,23 @@ func (c *CommandTranslator) Translate(query, contextInfo, mode string) (string,
 		command.Explanation = c.addHumorToExplanation(command.Explanation)
 	}
 
-	return command.Command, command.Explanation, nil
+	return command, nil
+}
+
+// restoreCommand puts redacted values back into every part of command
+func restoreCommand(session *redact.Session, command *domain.Command) {
+	command.Command = session.Restore(command.Command)
+	command.Explanation = session.Restore(command.Explanation)
+	for i, arg := range command.Arguments {
+		command.Arguments[i] = session.Restore(arg)
+	}
+	if len(command.Flags) > 0 {
+		flags := make(map[string]string, len(command.Flags))
+		for name, value := range command.Flags {
+			flags[session.Restore(name)] = session.Restore(value)
+		}
+		command.Flags = flags
+	}
 }
 
 // addHumorToExplanation adds some humor to the explanation
@@ -0,0 +1,36 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+
+	"github.com/arnislvdev/go-guru-ui/internal/domain"
+	"github.com/arnislvdev/go-guru-ui/pkg/redact"
+)
+
+func TestTranslateRedacts(t *testing.T) {
+	redactor, err := redact.New(nil)
+	if err != nil {
+		t.Fatalf("redact.New returned error: %v", err)
+	}
+	client := &fakeClient{command: &domain.Command{
+		Command:     "ssh [IP_1]",
+		Arguments:   []string{"[IP_1]"},
+		Explanation: "Opens a shell on [IP_1]",
+	}}
+	translator := NewCommandTranslator(client, WithRedactor(redactor))
+
+	command, err := translator.Translate(context.Background(), "log in to 10.0.0.7", "server 10.0.0.7", "professional")
+	if err != nil {
+		t.Fatalf("Translate returned error: %v", err)
+	}
+	if client.lastQuery != "log in to [IP_1]" || client.lastContext != "server [IP_1]" {
+		t.Errorf("the AI was sent %q with context %q", client.lastQuery, client.lastContext)
+	}
+	if command.Command != "ssh 10.0.0.7" || command.Arguments[0] != "10.0.0.7" || command.Explanation != "Opens a shell on 10.0.0.7" {
+		t.Errorf("command = %+v, want the address restored", command)
+	}
+	if command.Context != "server 10.0.0.7" {
+		t.Errorf("Context = %q, want the original", command.Context)
+	}
+}
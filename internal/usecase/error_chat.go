@@ -0,0 +1,70 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/arnislvdev/go-guru-ui/internal/domain"
+	"github.com/arnislvdev/go-guru-ui/internal/infrastructure/ai"
+	"github.com/arnislvdev/go-guru-ui/pkg/redact"
+)
+
+// ErrorChat handles follow-up questions about an error that was explained
+type ErrorChat struct {
+	aiClient ai.Client
+	redactor *redact.Redactor
+
+	// OnRedact, if set, is told how many values were hidden from the AI
+	OnRedact func(count int)
+}
+
+// NewErrorChat creates a new ErrorChat that asks the given AI client
+func NewErrorChat(client ai.Client, opts ...Option) *ErrorChat {
+	o := applyOptions(opts)
+	return &ErrorChat{
+		aiClient: client,
+		redactor: o.redactor,
+	}
+}
+
+// Start begins a conversation about err, seeded with its explanation
+func (c *ErrorChat) Start(err *domain.Error, explanation string) *domain.Conversation {
+	return &domain.Conversation{
+		Error:    err,
+		Messages: []domain.Message{{Role: domain.RoleAssistant, Content: explanation}},
+		Provider: c.aiClient.GetProvider(),
+	}
+}
+
+// Ask sends a follow-up question with the whole conversation so far and
+// adds the question and the reply to conv. A failed question leaves conv as it was.
+func (c *ErrorChat) Ask(ctx context.Context, conv *domain.Conversation, question string) (string, error) {
+	question = strings.TrimSpace(question)
+	if question == "" {
+		return "", errors.New("question is empty")
+	}
+	messages := append(append([]domain.Message(nil), conv.Messages...), domain.Message{Role: domain.RoleUser, Content: question})
+
+	// The history is kept as the user saw it and hidden again for every request
+	session := c.redactor.Session()
+	redacted := make([]domain.Message, len(messages))
+	for i, message := range messages {
+		redacted[i] = domain.Message{Role: message.Role, Content: session.Redact(message.Content)}
+	}
+	request := redactError(session, conv.Error)
+	if session != nil && c.OnRedact != nil {
+		c.OnRedact(session.Count())
+	}
+
+	reply, err := c.aiClient.Chat(ctx, request, redacted)
+	if err != nil {
+		return "", fmt.Errorf("AI follow-up failed: %w", err)
+	}
+	reply = session.Restore(reply)
+
+	conv.Messages = append(messages, domain.Message{Role: domain.RoleAssistant, Content: reply})
+	conv.Provider = c.aiClient.GetProvider()
+	return reply, nil
+}
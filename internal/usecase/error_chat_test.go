@@ -0,0 +1,68 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/arnislvdev/go-guru-ui/internal/domain"
+	"github.com/arnislvdev/go-guru-ui/pkg/redact"
+)
+
+func TestErrorChatAsk(t *testing.T) {
+	client := &fakeClient{reply: "Because fmt is a package."}
+	chat := NewErrorChat(client)
+
+	conv := chat.Start(&domain.Error{Message: "undefined: fmt"}, "Import fmt.")
+	reply, err := chat.Ask(context.Background(), conv, "  why?  ")
+	if err != nil {
+		t.Fatalf("Ask returned error: %v", err)
+	}
+	if reply != "Because fmt is a package." {
+		t.Errorf("reply = %q", reply)
+	}
+
+	want := []domain.Message{
+		{Role: domain.RoleAssistant, Content: "Import fmt."},
+		{Role: domain.RoleUser, Content: "why?"},
+	}
+	if len(client.lastMessages) != 2 || client.lastMessages[0] != want[0] || client.lastMessages[1] != want[1] {
+		t.Errorf("the AI was sent %+v, want %+v", client.lastMessages, want)
+	}
+	if len(conv.Messages) != 3 || conv.Messages[2].Content != reply || conv.Provider != "fake" {
+		t.Errorf("conversation = %+v", conv)
+	}
+
+	if _, err := chat.Ask(context.Background(), conv, " "); err == nil {
+		t.Error("Ask should reject an empty question")
+	}
+
+	client.err = errors.New("provider down")
+	if _, err := chat.Ask(context.Background(), conv, "and then?"); err == nil {
+		t.Error("Ask should return the AI error")
+	}
+	if len(conv.Messages) != 3 {
+		t.Errorf("a failed question should leave the conversation alone, got %d messages", len(conv.Messages))
+	}
+}
+
+func TestErrorChatRedacts(t *testing.T) {
+	redactor, err := redact.New(nil)
+	if err != nil {
+		t.Fatalf("redact.New returned error: %v", err)
+	}
+	client := &fakeClient{reply: "Ask [EMAIL_1] for access."}
+	chat := NewErrorChat(client, WithRedactor(redactor))
+
+	conv := chat.Start(&domain.Error{Message: "permission denied for bob@example.com"}, "bob@example.com can't read it.")
+	reply, err := chat.Ask(context.Background(), conv, "who should I ask?")
+	if err != nil {
+		t.Fatalf("Ask returned error: %v", err)
+	}
+	if client.lastError.Message != "permission denied for [EMAIL_1]" || client.lastMessages[0].Content != "[EMAIL_1] can't read it." {
+		t.Errorf("the AI was sent %q and %+v", client.lastError.Message, client.lastMessages)
+	}
+	if reply != "Ask bob@example.com for access." || conv.Messages[0].Content != "bob@example.com can't read it." {
+		t.Errorf("reply = %q, history = %+v; want the address restored", reply, conv.Messages)
+	}
+}
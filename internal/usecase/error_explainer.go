@@ -1,38 +1,63 @@
 package usecase
 
 import (
+	"context"
 	"fmt"
 	"strings"
 
 	"github.com/arnislvdev/go-guru-ui/internal/domain"
 	"github.com/arnislvdev/go-guru-ui/internal/infrastructure/ai"
+	"github.com/arnislvdev/go-guru-ui/internal/infrastructure/tools"
+	"github.com/arnislvdev/go-guru-ui/internal/parser"
 	"github.com/arnislvdev/go-guru-ui/pkg/humor"
+	"github.com/arnislvdev/go-guru-ui/pkg/redact"
 )
 
 // ErrorExplainer handles the business logic for explaining errors
 type ErrorExplainer struct {
 	aiClient ai.Client
 	humor    *humor.WTFMode
+	redactor *redact.Redactor
+	tools    []tools.Tool
+	maxSteps int
+
+	// OnRedact, if set, is told how many values were hidden from the AI
+	OnRedact func(count int)
+
+	// OnToolCall, if set, is told about each tool the AI runs and how it went
+	OnToolCall func(call ToolCall, err error)
 }
 
-// NewErrorExplainer creates a new ErrorExplainer instance
-func NewErrorExplainer() *ErrorExplainer {
+// NewErrorExplainer creates a new ErrorExplainer that asks the given AI client
+func NewErrorExplainer(client ai.Client, opts ...Option) *ErrorExplainer {
+	o := applyOptions(opts)
 	return &ErrorExplainer{
-		aiClient: ai.NewOpenAIClient(),
+		aiClient: client,
 		humor:    humor.NewWTFMode(),
+		redactor: o.redactor,
+		tools:    o.tools,
+		maxSteps: o.maxSteps,
 	}
 }
 
 // Explain explains an error message in the specified mode
-func (e *ErrorExplainer) Explain(errorMsg, file string, line int, mode string) (string, error) {
+func (e *ErrorExplainer) Explain(ctx context.Context, errorMsg, file string, line int, mode string) (string, error) {
 	// Parse the error to extract structured information
 	parsedError := e.parseError(errorMsg, file, line)
+	session := e.redactor.Session()
 
 	// Generate explanation using AI
-	explanation, err := e.aiClient.ExplainError(parsedError)
+	var explanation string
+	var err error
+	if len(e.tools) > 0 {
+		explanation, err = e.investigate(ctx, session, e.redact(session, parsedError))
+	} else {
+		explanation, err = e.aiClient.ExplainError(ctx, e.redact(session, parsedError))
+	}
 	if err != nil {
 		return "", fmt.Errorf("AI explanation failed: %w", err)
 	}
+	explanation = session.Restore(explanation)
 
 	// Apply mode-specific formatting
 	if mode == "wtf" {
@@ -42,8 +67,116 @@ func (e *ErrorExplainer) Explain(errorMsg, file string, line int, mode string) (
 	return explanation, nil
 }
 
-// parseError extracts structured information from error messages
+// ExplainStream explains an error like Explain, but passes the text to onChunk as the AI
+// writes it. In WTF mode the prefix comes with the first chunk and the suffix after the last.
+func (e *ErrorExplainer) ExplainStream(ctx context.Context, errorMsg, file string, line int, mode string, onChunk func(string)) error {
+	return e.ExplainErrorStream(ctx, e.parseError(errorMsg, file, line), mode, onChunk)
+}
+
+// ExplainErrorStream is ExplainStream for an error that is already parsed, such
+// as one diagnostic from compiler output. An empty Type or Severity is filled in.
+func (e *ErrorExplainer) ExplainErrorStream(ctx context.Context, parsedError *domain.Error, mode string, onChunk func(string)) error {
+	parsedError = e.classify(parsedError)
+	session := e.redactor.Session()
+	request := e.redact(session, parsedError)
+
+	emit := onChunk
+	if mode == "wtf" {
+		// Hold the prefix back until the AI answers so a failure doesn't print a joke
+		started := false
+		emit = func(chunk string) {
+			if !started {
+				started = true
+				onChunk(e.humor.Prefix(parsedError.Type) + "\n\n")
+			}
+			onChunk(chunk)
+		}
+	}
+
+	if len(e.tools) > 0 {
+		// Tool calls aren't for the user to see, so the answer comes in one piece.
+		// Tool output may have added placeholders, so restore only once it's done.
+		explanation, err := e.investigate(ctx, session, request)
+		if err != nil {
+			return fmt.Errorf("AI explanation failed: %w", err)
+		}
+		emit(session.Restore(explanation))
+	} else {
+		write, flush := session.Stream(emit)
+		if _, err := ai.StreamExplanation(ctx, e.aiClient, request, write); err != nil {
+			return fmt.Errorf("AI explanation failed: %w", err)
+		}
+		flush()
+	}
+
+	if mode == "wtf" {
+		onChunk("\n\n" + e.humor.Suffix(parsedError.Type))
+	}
+
+	return nil
+}
+
+// Parse returns the structured error that Explain sends to the AI
+func (e *ErrorExplainer) Parse(errorMsg, file string, line int) *domain.Error {
+	return e.parseError(errorMsg, file, line)
+}
+
+// redact returns a copy of err with its message and file redacted by session
+func (e *ErrorExplainer) redact(session *redact.Session, err *domain.Error) *domain.Error {
+	redacted := redactError(session, err)
+	if session != nil && e.OnRedact != nil {
+		e.OnRedact(session.Count())
+	}
+	return redacted
+}
+
+// redactError returns a copy of err with every field that holds text from the
+// error output redacted by session: a build's package can be a path, and an
+// exception's class can carry its module's name
+func redactError(session *redact.Session, err *domain.Error) *domain.Error {
+	if session == nil || err == nil {
+		return err
+	}
+
+	redacted := *err
+	redacted.Message = session.Redact(err.Message)
+	redacted.File = session.Redact(err.File)
+	redacted.Package = session.Redact(err.Package)
+	redacted.Exception = session.Redact(err.Exception)
+	redacted.PanicValue = session.Redact(err.PanicValue)
+	if err.Causes != nil {
+		redacted.Causes = make([]string, len(err.Causes))
+		for i, cause := range err.Causes {
+			redacted.Causes[i] = session.Redact(cause)
+		}
+	}
+	if err.Stack != nil {
+		redacted.Stack = make([]domain.Frame, len(err.Stack))
+		for i, frame := range err.Stack {
+			frame.Function = session.Redact(frame.Function)
+			frame.File = session.Redact(frame.File)
+			redacted.Stack[i] = frame
+		}
+	}
+	return &redacted
+}
+
+// parseError extracts structured information from error messages. A Go panic,
+// Node.js exception or Python traceback keeps its stack and points at the
+// first call in the program's code, and a tsc diagnostic keeps its place and
+// code, unless file says where to look.
 func (e *ErrorExplainer) parseError(errorMsg, file string, line int) *domain.Error {
+	parsed, ok := parser.Parse(errorMsg)
+	if output := parser.TypeScript(errorMsg); !ok && len(output.Errors) == 1 {
+		parsed, ok = output.Errors[0], true
+	}
+	if ok {
+		if file != "" {
+			parsed.File, parsed.Line, parsed.Column = file, line, 0
+		}
+		return parsed
+	}
+
 	errorType := e.detectErrorType(errorMsg)
 
 	return &domain.Error{
@@ -56,6 +189,25 @@ func (e *ErrorExplainer) parseError(errorMsg, file string, line int) *domain.Err
 	}
 }
 
+// classify returns err with its Type and Severity filled in when they are empty
+func (e *ErrorExplainer) classify(err *domain.Error) *domain.Error {
+	if err.Type != "" && err.Severity != "" {
+		return err
+	}
+
+	classified := *err
+	if classified.Type == "" {
+		classified.Type = e.detectErrorType(err.Message)
+	}
+	if classified.Severity == "" {
+		classified.Severity = e.determineSeverity(classified.Type)
+	}
+	if classified.Language == "" {
+		classified.Language = domain.LanguageGo
+	}
+	return &classified
+}
+
 // detectErrorType identifies the type of error
 func (e *ErrorExplainer) detectErrorType(errorMsg string) string {
 	errorMsg = strings.ToLower(errorMsg)
@@ -91,6 +243,11 @@ func (e *ErrorExplainer) determineSeverity(errorType string) string {
 		return "error"
 	case "argument_count":
 		return "error"
+	case "syntax_error", "import_error", "null_reference", "lookup_error",
+		"value_error", "arithmetic_error", "io_error", "resource_error":
+		return "error"
+	case "runtime_panic":
+		return "fatal"
 	default:
 		return "info"
 	}
@@ -1,16 +1,258 @@
 package usecase
 
 import (
+	"context"
+	"errors"
+	"strings"
 	"testing"
+
+	"github.com/arnislvdev/go-guru-ui/internal/domain"
+	"github.com/arnislvdev/go-guru-ui/pkg/redact"
 )
 
+// fakeClient is an ai.Client that returns canned answers and remembers what it was asked
+type fakeClient struct {
+	explanation string
+	command     *domain.Command
+	reply       string
+	replies     []string // answered in turn by Chat before falling back to reply
+	err         error
+
+	lastError    *domain.Error
+	lastQuery    string
+	lastContext  string
+	lastMessages []domain.Message
+}
+
+func (f *fakeClient) ExplainError(ctx context.Context, err *domain.Error) (string, error) {
+	f.lastError = err
+	return f.explanation, f.err
+}
+
+func (f *fakeClient) TranslateQuery(ctx context.Context, query, contextInfo string) (*domain.Command, error) {
+	f.lastQuery, f.lastContext = query, contextInfo
+	return f.command, f.err
+}
+
+func (f *fakeClient) Chat(ctx context.Context, err *domain.Error, messages []domain.Message) (string, error) {
+	f.lastError, f.lastMessages = err, messages
+	if len(f.replies) > 0 {
+		reply := f.replies[0]
+		f.replies = f.replies[1:]
+		return reply, f.err
+	}
+	return f.reply, f.err
+}
+
+func (f *fakeClient) GetProvider() string {
+	return "fake"
+}
+
 func TestNewErrorExplainer(t *testing.T) {
-	explainer := NewErrorExplainer()
+	explainer := NewErrorExplainer(&fakeClient{})
 	if explainer == nil {
 		t.Error("NewErrorExplainer should not return nil")
 	}
 }
 
+func TestExplain(t *testing.T) {
+	client := &fakeClient{explanation: "Import the fmt package."}
+	explainer := NewErrorExplainer(client)
+
+	explanation, err := explainer.Explain(context.Background(), "undefined: fmt", "main.go", 3, "professional")
+	if err != nil {
+		t.Fatalf("Explain returned error: %v", err)
+	}
+	if explanation != "Import the fmt package." {
+		t.Errorf("explanation = %q", explanation)
+	}
+	if client.lastError.Type != domain.ErrorTypeUndefinedSymbol || client.lastError.File != "main.go" || client.lastError.Line != 3 {
+		t.Errorf("parsed error = %+v", client.lastError)
+	}
+
+	wtf, err := explainer.Explain(context.Background(), "undefined: fmt", "", 0, "wtf")
+	if err != nil {
+		t.Fatalf("Explain in wtf mode returned error: %v", err)
+	}
+	if !strings.Contains(wtf, "Import the fmt package.") || wtf == "Import the fmt package." {
+		t.Errorf("wtf explanation should wrap the answer in humor, got %q", wtf)
+	}
+}
+
+func TestExplainStreamWTFOrder(t *testing.T) {
+	explainer := NewErrorExplainer(&fakeClient{explanation: "Import the fmt package."})
+
+	var chunks []string
+	err := explainer.ExplainStream(context.Background(), "undefined: fmt", "", 0, "wtf", func(chunk string) {
+		chunks = append(chunks, chunk)
+	})
+	if err != nil {
+		t.Fatalf("ExplainStream returned error: %v", err)
+	}
+	if len(chunks) != 3 || chunks[1] != "Import the fmt package." {
+		t.Fatalf("chunks = %q, want prefix, explanation, suffix", chunks)
+	}
+}
+
+func TestExplainStreamErrorPrintsNothing(t *testing.T) {
+	explainer := NewErrorExplainer(&fakeClient{err: errors.New("provider down")})
+
+	var chunks []string
+	err := explainer.ExplainStream(context.Background(), "undefined: fmt", "", 0, "wtf", func(chunk string) {
+		chunks = append(chunks, chunk)
+	})
+	if err == nil {
+		t.Fatal("ExplainStream should return the AI error")
+	}
+	if len(chunks) != 0 {
+		t.Errorf("nothing should be printed on failure, got %q", chunks)
+	}
+}
+
+func TestExplainErrorStreamClassifies(t *testing.T) {
+	client := &fakeClient{explanation: "Remove the import."}
+	explainer := NewErrorExplainer(client)
+
+	parsed := &domain.Error{Message: `"os" imported and not used`, File: "main.go", Line: 3, Column: 2}
+	err := explainer.ExplainErrorStream(context.Background(), parsed, "professional", func(string) {})
+	if err != nil {
+		t.Fatalf("ExplainErrorStream returned error: %v", err)
+	}
+
+	got := client.lastError
+	if got.Type != domain.ErrorTypeUnusedImport || got.Severity != domain.SeverityWarning || got.Language != domain.LanguageGo {
+		t.Errorf("sent %+v, want it classified as an unused Go import", got)
+	}
+	if got.Column != 2 || parsed.Type != "" {
+		t.Errorf("sent column %d and parsed type %q, want column 2 and the caller's error left alone", got.Column, parsed.Type)
+	}
+}
+
+func TestExplainPanic(t *testing.T) {
+	redactor, err := redact.New(nil)
+	if err != nil {
+		t.Fatalf("redact.New returned error: %v", err)
+	}
+	client := &fakeClient{explanation: "Make the map first."}
+	explainer := NewErrorExplainer(client, WithRedactor(redactor))
+
+	trace := "panic: assignment to entry in nil map\n\ngoroutine 1 [running]:\n" +
+		"main.add(...)\n\t/home/bob/app/main.go:9\n" +
+		"main.main()\n\t/home/bob/app/main.go:14 +0x2c\nexit status 2\n"
+	if _, err := explainer.Explain(context.Background(), trace, "", 0, "professional"); err != nil {
+		t.Fatalf("Explain returned error: %v", err)
+	}
+
+	got := client.lastError
+	if got.Type != domain.ErrorTypeRuntimePanic || got.RuntimeKind != domain.RuntimeNilMapWrite || got.Line != 9 {
+		t.Errorf("sent %+v, want a nil map panic at line 9", got)
+	}
+	if len(got.Stack) != 2 || strings.Contains(got.Stack[1].File, "/home/bob/") || strings.Contains(got.File, "/home/bob/") {
+		t.Errorf("sent stack %+v from %s, want two calls with the home folder hidden", got.Stack, got.File)
+	}
+}
+
+func TestExplainPythonTraceback(t *testing.T) {
+	redactor, err := redact.New(nil)
+	if err != nil {
+		t.Fatalf("redact.New returned error: %v", err)
+	}
+	client := &fakeClient{explanation: "Check the key first."}
+	explainer := NewErrorExplainer(client, WithRedactor(redactor))
+
+	trace := "Traceback (most recent call last):\n" +
+		"  File \"/home/bob/app/users.py\", line 4, in find\n    return users[email]\n" +
+		"KeyError: 'bob@example.com'\n\n" +
+		"During handling of the above exception, another exception occurred:\n\n" +
+		"Traceback (most recent call last):\n" +
+		"  File \"/home/bob/app/users.py\", line 6, in find\n    raise LookupError(\"no such user\")\n" +
+		"LookupError: no such user\n"
+	if _, err := explainer.Explain(context.Background(), trace, "", 0, "professional"); err != nil {
+		t.Fatalf("Explain returned error: %v", err)
+	}
+
+	got := client.lastError
+	if got.Language != domain.LanguagePython || got.Exception != "LookupError" || got.Type != domain.ErrorTypeLookup || got.Line != 6 {
+		t.Errorf("sent %+v, want a Python LookupError at line 6", got)
+	}
+	if len(got.Causes) != 1 || strings.Contains(got.Causes[0], "bob@example.com") || strings.Contains(got.File, "/home/bob/") {
+		t.Errorf("sent causes %q from %s, want the email and home folder hidden", got.Causes, got.File)
+	}
+}
+
+func TestExplainErrorStreamRedactsPackage(t *testing.T) {
+	redactor, err := redact.New(nil)
+	if err != nil {
+		t.Fatalf("redact.New returned error: %v", err)
+	}
+	client := &fakeClient{explanation: "Remove the import."}
+	explainer := NewErrorExplainer(client, WithRedactor(redactor))
+
+	// go build names packages outside a module by their folder
+	parsed := &domain.Error{Message: `"os" imported and not used`, Package: "/home/bob/scratch", File: "/home/bob/scratch/main.go", Line: 3}
+	if err := explainer.ExplainErrorStream(context.Background(), parsed, "professional", func(string) {}); err != nil {
+		t.Fatalf("ExplainErrorStream returned error: %v", err)
+	}
+	if got := client.lastError; strings.Contains(got.Package, "/home/bob/") {
+		t.Errorf("sent package %q, want the home folder hidden", got.Package)
+	}
+}
+
+func TestExplainTypeScriptDiagnostic(t *testing.T) {
+	client := &fakeClient{explanation: "Pass a number."}
+	explainer := NewErrorExplainer(client)
+
+	msg := "src/cart.ts(7,12): error TS2345: Argument of type 'string' is not assignable to parameter of type 'number'."
+	if _, err := explainer.Explain(context.Background(), msg, "", 0, "professional"); err != nil {
+		t.Fatalf("Explain returned error: %v", err)
+	}
+
+	got := client.lastError
+	if got.Language != domain.LanguageTS || got.Code != "TS2345" || got.Type != domain.ErrorTypeTypeMismatch {
+		t.Errorf("sent %+v, want a TypeScript type mismatch", got)
+	}
+	if got.File != "src/cart.ts" || got.Line != 7 || got.Column != 12 {
+		t.Errorf("sent location %s:%d:%d, want src/cart.ts:7:12", got.File, got.Line, got.Column)
+	}
+}
+
+func TestExplainRedacts(t *testing.T) {
+	redactor, err := redact.New(nil)
+	if err != nil {
+		t.Fatalf("redact.New returned error: %v", err)
+	}
+	client := &fakeClient{explanation: "Check the password for [EMAIL_1]."}
+	explainer := NewErrorExplainer(client, WithRedactor(redactor))
+
+	hidden := 0
+	explainer.OnRedact = func(count int) { hidden = count }
+
+	explanation, err := explainer.Explain(context.Background(), "login failed for bob@example.com", "/home/bob/app/main.go", 3, "professional")
+	if err != nil {
+		t.Fatalf("Explain returned error: %v", err)
+	}
+	if strings.Contains(client.lastError.Message, "bob@example.com") || strings.Contains(client.lastError.File, "/home/bob/") {
+		t.Errorf("the AI was sent %+v", client.lastError)
+	}
+	if explanation != "Check the password for bob@example.com." {
+		t.Errorf("explanation = %q, want the address restored", explanation)
+	}
+	if hidden != 2 {
+		t.Errorf("OnRedact got %d, want 2", hidden)
+	}
+
+	var streamed strings.Builder
+	err = explainer.ExplainStream(context.Background(), "login failed for bob@example.com", "", 0, "professional", func(chunk string) {
+		streamed.WriteString(chunk)
+	})
+	if err != nil {
+		t.Fatalf("ExplainStream returned error: %v", err)
+	}
+	if streamed.String() != "Check the password for bob@example.com." {
+		t.Errorf("streamed = %q, want the address restored", streamed.String())
+	}
+}
+
 func TestDetectErrorType(t *testing.T) {
 	explainer := &ErrorExplainer{}
 
@@ -0,0 +1,275 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/arnislvdev/go-guru-ui/internal/domain"
+	"github.com/arnislvdev/go-guru-ui/internal/parser"
+)
+
+const (
+	// leakCount is how many goroutines stuck in the same place look like a leak
+	leakCount = 10
+
+	// maxGroups and maxGroupFrames keep the summary short enough to send
+	maxGroups      = 15
+	maxGroupFrames = 5
+)
+
+// blockingStates are wait reasons that only end when another goroutine acts
+var blockingStates = map[string]bool{
+	"chan receive":        true,
+	"chan send":           true,
+	"select":              true,
+	"select (no cases)":   true,
+	"semacquire":          true,
+	"sync.Cond.Wait":      true,
+	"sync.WaitGroup.Wait": true,
+}
+
+// GoroutineGroup is goroutines with the same stack and wait reason
+type GoroutineGroup struct {
+	State     string
+	Count     int
+	MinWait   time.Duration
+	MaxWait   time.Duration
+	Stack     []domain.Frame
+	CreatedBy string
+}
+
+// GoroutineReport sums up a goroutine dump: what the goroutines are doing,
+// and what looks wrong
+type GoroutineReport struct {
+	Total    int
+	Groups   []GoroutineGroup // largest first
+	Findings []string
+	Deadlock bool // reported by the runtime
+}
+
+// AnalyzeGoroutines groups the goroutines in dump by stack and wait reason and
+// looks for deadlocks, goroutines waiting on each other's locks, and leaks
+func AnalyzeGoroutines(dump parser.Dump) *GoroutineReport {
+	report := &GoroutineReport{Total: len(dump.Goroutines), Deadlock: dump.Deadlock}
+
+	index := make(map[string]int)
+	for _, g := range dump.Goroutines {
+		key := groupKey(g)
+		i, ok := index[key]
+		if !ok {
+			i = len(report.Groups)
+			index[key] = i
+			report.Groups = append(report.Groups, GoroutineGroup{
+				State:     g.State,
+				MinWait:   g.Wait,
+				Stack:     g.Stack,
+				CreatedBy: g.CreatedBy,
+			})
+		}
+
+		group := &report.Groups[i]
+		group.Count++
+		if g.Wait < group.MinWait {
+			group.MinWait = g.Wait
+		}
+		if g.Wait > group.MaxWait {
+			group.MaxWait = g.Wait
+		}
+	}
+	sort.SliceStable(report.Groups, func(i, j int) bool {
+		return report.Groups[i].Count > report.Groups[j].Count
+	})
+
+	if dump.Deadlock {
+		report.Findings = append(report.Findings, "The runtime found a deadlock: every goroutine is blocked and none can wake the others.")
+	}
+	report.Findings = append(report.Findings, lockFindings(dump)...)
+	for _, group := range report.Groups {
+		if finding := leakFinding(group); finding != "" {
+			report.Findings = append(report.Findings, finding)
+		}
+	}
+	return report
+}
+
+// groupKey identifies goroutines that are stuck in the same place for the same reason
+func groupKey(g parser.Goroutine) string {
+	var key strings.Builder
+	key.WriteString(g.State)
+	for _, frame := range g.Stack {
+		fmt.Fprintf(&key, "|%s:%s:%d", frame.Function, frame.File, frame.Line)
+	}
+	key.WriteString("|" + g.CreatedBy)
+	return key.String()
+}
+
+// lockFindings reports goroutines blocked for minutes on mutexes. A dump
+// doesn't show who holds a lock, so waits on different mutexes are only
+// called a lock cycle when the runtime found a deadlock; in a live process
+// they may just be busy locks.
+func lockFindings(dump parser.Dump) []string {
+	waiters := make(map[string][]parser.Goroutine)
+	var mutexes []string
+	for _, g := range dump.Goroutines {
+		if g.Mutex == "" || (g.Wait < time.Minute && !dump.Deadlock) {
+			continue
+		}
+		if _, ok := waiters[g.Mutex]; !ok {
+			mutexes = append(mutexes, g.Mutex)
+		}
+		waiters[g.Mutex] = append(waiters[g.Mutex], g)
+	}
+
+	switch {
+	case len(mutexes) >= 2 && dump.Deadlock:
+		var parts []string
+		for _, mutex := range mutexes {
+			g := waiters[mutex][0]
+			parts = append(parts, fmt.Sprintf("goroutine %d waits for mutex %s%s", g.ID, mutex, where(g.Stack)))
+		}
+		return []string{fmt.Sprintf("Likely lock cycle: %s. If each holds a lock another is waiting for, none can continue; take the locks in the same order everywhere.",
+			strings.Join(parts, "; "))}
+	case len(mutexes) >= 2:
+		var all []parser.Goroutine
+		var parts []string
+		for _, mutex := range mutexes {
+			all = append(all, waiters[mutex]...)
+			parts = append(parts, mutex+where(waiters[mutex][0].Stack))
+		}
+		min, max := waitRange(all)
+		return []string{fmt.Sprintf("%d goroutines have waited %s on mutexes %s; the dump doesn't show who holds them.",
+			len(all), formatWait(min, max), strings.Join(parts, ", "))}
+	case len(mutexes) == 1:
+		mutex := mutexes[0]
+		min, max := waitRange(waiters[mutex])
+		return []string{fmt.Sprintf("%d goroutine(s) have waited %s for mutex %s%s; whoever holds it has not unlocked it.",
+			len(waiters[mutex]), formatWait(min, max), mutex, where(waiters[mutex][0].Stack))}
+	}
+	return nil
+}
+
+// waitRange returns the shortest and longest wait of goroutines
+func waitRange(goroutines []parser.Goroutine) (min, max time.Duration) {
+	for i, g := range goroutines {
+		if i == 0 || g.Wait < min {
+			min = g.Wait
+		}
+		if g.Wait > max {
+			max = g.Wait
+		}
+	}
+	return min, max
+}
+
+// leakFinding describes a group that looks leaked, or returns ""
+func leakFinding(group GoroutineGroup) string {
+	started := ""
+	if group.CreatedBy != "" {
+		started = " started by " + group.CreatedBy
+	}
+
+	switch {
+	case strings.Contains(group.State, "(nil chan)"):
+		return fmt.Sprintf("%d goroutine(s)%s block forever on a %s%s: operations on a nil channel never finish.",
+			group.Count, started, strings.TrimSuffix(group.State, " (nil chan)"), where(group.Stack))
+	case group.State == "select (no cases)":
+		return fmt.Sprintf("%d goroutine(s)%s block forever in an empty select%s.", group.Count, started, where(group.Stack))
+	case blockingStates[group.State] && group.Count >= leakCount && group.MaxWait >= time.Minute:
+		return fmt.Sprintf("Possible leak: %d goroutines%s have waited %s on %s%s with nothing to wake them.",
+			group.Count, started, formatWait(group.MinWait, group.MaxWait), group.State, where(group.Stack))
+	}
+	return ""
+}
+
+// Summary writes the report as compact text, to show the user and send to the AI
+func (r *GoroutineReport) Summary() string {
+	var out strings.Builder
+	if r.Deadlock {
+		out.WriteString("fatal error: all goroutines are asleep - deadlock!\n")
+	}
+	fmt.Fprintf(&out, "Goroutine dump: %d goroutines in %d groups.\n", r.Total, len(r.Groups))
+
+	if len(r.Findings) > 0 {
+		out.WriteString("\nFindings:\n")
+		for _, finding := range r.Findings {
+			fmt.Fprintf(&out, "- %s\n", finding)
+		}
+	}
+
+	out.WriteString("\nGroups, largest first:\n")
+	for i, group := range r.Groups {
+		if i == maxGroups {
+			rest := 0
+			for _, g := range r.Groups[i:] {
+				rest += g.Count
+			}
+			fmt.Fprintf(&out, "- ... %d more groups with %d goroutines\n", len(r.Groups)-i, rest)
+			break
+		}
+
+		fmt.Fprintf(&out, "- %d x %s", group.Count, group.State)
+		if group.MaxWait > 0 {
+			fmt.Fprintf(&out, ", waiting %s", formatWait(group.MinWait, group.MaxWait))
+		}
+		if group.CreatedBy != "" {
+			fmt.Fprintf(&out, ", started by %s", group.CreatedBy)
+		}
+		out.WriteString("\n")
+		for _, frame := range summaryFrames(group.Stack) {
+			fmt.Fprintf(&out, "    %s (%s:%d)\n", frame.Function, frame.File, frame.Line)
+		}
+	}
+	return strings.TrimRight(out.String(), "\n")
+}
+
+// summaryFrames drops the runtime's own calls, which the wait reason already
+// explains, and keeps the innermost few of the rest
+func summaryFrames(stack []domain.Frame) []domain.Frame {
+	var frames []domain.Frame
+	for _, frame := range stack {
+		if strings.HasPrefix(frame.Function, "runtime.") || strings.Contains(frame.Function, ".runtime_") {
+			continue
+		}
+		frames = append(frames, frame)
+		if len(frames) == maxGroupFrames {
+			break
+		}
+	}
+	return frames
+}
+
+// where names the first call in the program's code, as " in main.run (main.go:12)"
+func where(stack []domain.Frame) string {
+	frame := parser.FirstUserFrame(stack)
+	if frame == nil {
+		return ""
+	}
+	return fmt.Sprintf(" in %s (%s:%d)", frame.Function, frame.File, frame.Line)
+}
+
+// formatWait shows a wait or a range of waits in minutes; the runtime reports nothing finer
+func formatWait(min, max time.Duration) string {
+	if min == max {
+		return fmt.Sprintf("%d min", int(max.Minutes()))
+	}
+	return fmt.Sprintf("%d-%d min", int(min.Minutes()), int(max.Minutes()))
+}
+
+// ExplainGoroutines asks the AI what a goroutine dump shows, sending the
+// report's summary rather than the dump itself
+func (e *ErrorExplainer) ExplainGoroutines(ctx context.Context, report *GoroutineReport, mode string, onChunk func(string)) error {
+	err := &domain.Error{
+		Message:  report.Summary(),
+		Type:     domain.ErrorTypeGoroutineDump,
+		Severity: domain.SeverityError,
+		Language: domain.LanguageGo,
+	}
+	if report.Deadlock {
+		err.Severity = domain.SeverityFatal
+		err.RuntimeKind = domain.RuntimeDeadlock
+	}
+	return e.ExplainErrorStream(ctx, err, mode, onChunk)
+}
@@ -0,0 +1,88 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/arnislvdev/go-guru-ui/internal/domain"
+	"github.com/arnislvdev/go-guru-ui/internal/parser"
+)
+
+// lockedGoroutine is a goroutine dump entry blocked on the mutex at addr
+func lockedGoroutine(id int, addr string) string {
+	return fmt.Sprintf("goroutine %d [sync.Mutex.Lock, 3 minutes]:\n"+
+		"internal/sync.runtime_SemacquireMutex(0x0?, 0x0?, 0x0?)\n\t/usr/local/go/src/runtime/sema.go:95 +0x25\n"+
+		"internal/sync.(*Mutex).lockSlow(%s)\n\t/usr/local/go/src/internal/sync/mutex.go:149 +0x15a\n"+
+		"internal/sync.(*Mutex).Lock(...)\n\t/usr/local/go/src/internal/sync/mutex.go:70\n"+
+		"sync.(*Mutex).Lock(...)\n\t/usr/local/go/src/sync/mutex.go:46\n"+
+		"main.transfer(%s)\n\t/srv/app/bank.go:%d +0x6b\n\n", id, addr, addr, 20+id)
+}
+
+func TestAnalyzeGoroutines(t *testing.T) {
+	var dump strings.Builder
+	dump.WriteString("goroutine 1 [running]:\nmain.main()\n\t/srv/app/main.go:9 +0x1d\n\n")
+	for id := 10; id < 22; id++ {
+		fmt.Fprintf(&dump, "goroutine %d [chan send, %d minutes]:\nmain.produce(0xc000010000)\n\t/srv/app/produce.go:14 +0x2f\n"+
+			"created by main.main in goroutine 1\n\t/srv/app/main.go:7 +0x4a\n\n", id, id)
+	}
+	dump.WriteString(lockedGoroutine(3, "0xc0000a0000"))
+	dump.WriteString(lockedGoroutine(4, "0xc0000b0000"))
+
+	report := AnalyzeGoroutines(parser.GoroutineDump(dump.String()))
+	if report.Total != 15 || len(report.Groups) != 4 {
+		t.Fatalf("report = %d goroutines in %d groups, want 15 in 4", report.Total, len(report.Groups))
+	}
+
+	producers := report.Groups[0]
+	if producers.Count != 12 || producers.State != "chan send" || producers.MinWait.Minutes() != 10 || producers.MaxWait.Minutes() != 21 {
+		t.Errorf("largest group = %+v, want the 12 producers waiting 10-21 minutes", producers)
+	}
+
+	if len(report.Findings) != 2 ||
+		report.Findings[0] != "2 goroutines have waited 3 min on mutexes 0xc0000a0000 in main.transfer (/srv/app/bank.go:23), "+
+			"0xc0000b0000 in main.transfer (/srv/app/bank.go:24); the dump doesn't show who holds them." ||
+		!strings.HasPrefix(report.Findings[1], "Possible leak: 12 goroutines started by main.main have waited 10-21 min on chan send") {
+		t.Errorf("findings = %q", report.Findings)
+	}
+
+	summary := report.Summary()
+	for _, want := range []string{"Goroutine dump: 15 goroutines in 4 groups.", "- 12 x chan send, waiting 10-21 min, started by main.main\n    main.produce (/srv/app/produce.go:14)"} {
+		if !strings.Contains(summary, want) {
+			t.Errorf("summary = %q, want it to contain %q", summary, want)
+		}
+	}
+	if strings.Contains(summary, "runtime_SemacquireMutex") {
+		t.Errorf("summary = %q, want the runtime's own calls left out", summary)
+	}
+}
+
+func TestExplainGoroutinesSendsSummary(t *testing.T) {
+	client := &fakeClient{explanation: "Both goroutines wait on each other."}
+	explainer := NewErrorExplainer(client)
+
+	dump := "fatal error: all goroutines are asleep - deadlock!\n\n" + lockedGoroutine(1, "0xc0000a0000") + lockedGoroutine(2, "0xc0000b0000")
+	report := AnalyzeGoroutines(parser.GoroutineDump(dump))
+	if err := explainer.ExplainGoroutines(context.Background(), report, "professional", func(string) {}); err != nil {
+		t.Fatalf("ExplainGoroutines returned error: %v", err)
+	}
+
+	got := client.lastError
+	if got.Type != domain.ErrorTypeGoroutineDump || got.RuntimeKind != domain.RuntimeDeadlock || got.Severity != domain.SeverityFatal {
+		t.Errorf("sent %+v, want a fatal deadlock dump", got)
+	}
+	if got.Message != report.Summary() || !strings.Contains(got.Message, "The runtime found a deadlock") ||
+		!strings.Contains(got.Message, "Likely lock cycle: goroutine 1 waits for mutex 0xc0000a0000 in main.transfer (/srv/app/bank.go:21)") {
+		t.Errorf("sent message %q, want the report summary with the lock cycle", got.Message)
+	}
+}
+
+func TestAnalyzeGoroutinesOneMutex(t *testing.T) {
+	dump := lockedGoroutine(3, "0xc0000a0000") + strings.Replace(lockedGoroutine(4, "0xc0000a0000"), "3 minutes", "8 minutes", 1)
+
+	report := AnalyzeGoroutines(parser.GoroutineDump(dump))
+	if len(report.Findings) != 1 || !strings.HasPrefix(report.Findings[0], "2 goroutine(s) have waited 3-8 min for mutex 0xc0000a0000 in main.transfer") {
+		t.Errorf("findings = %q, want both waits on the one mutex", report.Findings)
+	}
+}
@@ -0,0 +1,152 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/arnislvdev/go-guru-ui/internal/domain"
+	"github.com/arnislvdev/go-guru-ui/internal/infrastructure/ai"
+	"github.com/arnislvdev/go-guru-ui/pkg/redact"
+)
+
+// defaultMaxSteps is how many tools the AI may run when no limit is given
+const defaultMaxSteps = 5
+
+// ToolCall is one request from the AI to run a tool
+type ToolCall struct {
+	Tool string            `json:"tool"`
+	Args map[string]string `json:"args"`
+}
+
+// String shows the call the way verbose mode prints it, e.g. read_file path=main.go start=1
+func (c ToolCall) String() string {
+	names := make([]string, 0, len(c.Args))
+	for name := range c.Args {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	parts := []string{c.Tool}
+	for _, name := range names {
+		parts = append(parts, name+"="+c.Args[name])
+	}
+	return strings.Join(parts, " ")
+}
+
+// investigate explains err, letting the AI run tools first. Each reply is
+// either a tool call, whose result goes back as the next message, or the
+// explanation. Tool output is redacted like the error; the explanation is
+// returned as the AI wrote it, placeholders and all. The conversation uses the
+// model for explanations; a provider that can't converse just explains.
+func (e *ErrorExplainer) investigate(ctx context.Context, session *redact.Session, err *domain.Error) (string, error) {
+	maxSteps := e.maxSteps
+	if maxSteps <= 0 {
+		maxSteps = defaultMaxSteps
+	}
+
+	ctx = ai.WithChatTask(ctx, ai.TaskExplain)
+	messages := []domain.Message{{Role: domain.RoleUser, Content: e.toolInstructions(maxSteps)}}
+	for step := 0; ; step++ {
+		reply, chatErr := e.aiClient.Chat(ctx, err, messages)
+		if step == 0 && errors.Is(chatErr, ai.ErrChatUnsupported) {
+			return e.aiClient.ExplainError(ctx, err)
+		}
+		if chatErr != nil {
+			return "", chatErr
+		}
+
+		call, ok := parseToolCall(reply)
+		if !ok {
+			return reply, nil
+		}
+		if step >= maxSteps {
+			if step > maxSteps {
+				return "", errors.New("the AI kept asking for tools after its last step")
+			}
+			messages = append(messages,
+				domain.Message{Role: domain.RoleAssistant, Content: reply},
+				domain.Message{Role: domain.RoleUser, Content: "That was the last tool you can use. Write your explanation now."})
+			continue
+		}
+
+		result := e.runTool(ctx, session, call)
+		messages = append(messages,
+			domain.Message{Role: domain.RoleAssistant, Content: reply},
+			domain.Message{Role: domain.RoleUser, Content: fmt.Sprintf("Result of %s:\n```\n%s\n```", call.Tool, strings.TrimRight(session.Redact(result), "\n"))})
+	}
+}
+
+// runTool runs one call and returns what to tell the AI. A failed call is
+// reported to the AI too, so it can try something else.
+func (e *ErrorExplainer) runTool(ctx context.Context, session *redact.Session, call ToolCall) string {
+	// The AI only knows the placeholders; the tools need the real values
+	for name, value := range call.Args {
+		call.Args[name] = session.Restore(value)
+	}
+
+	for _, tool := range e.tools {
+		if tool.Name() != call.Tool {
+			continue
+		}
+		out, err := tool.Run(ctx, call.Args)
+		if e.OnToolCall != nil {
+			e.OnToolCall(call, err)
+		}
+		if err != nil {
+			return "error: " + err.Error()
+		}
+		return out
+	}
+
+	err := fmt.Errorf("no tool named %q", call.Tool)
+	if e.OnToolCall != nil {
+		e.OnToolCall(call, err)
+	}
+	return "error: " + err.Error()
+}
+
+// toolInstructions tells the AI which tools it has and how to call them
+func (e *ErrorExplainer) toolInstructions(maxSteps int) string {
+	var b strings.Builder
+	b.WriteString("Before you explain, you may look at the user's project with these read-only tools:\n")
+	for _, tool := range e.tools {
+		fmt.Fprintf(&b, "- %s %s\n", tool.Name(), tool.Usage())
+	}
+	fmt.Fprintf(&b, "\nTo use a tool, reply with only a JSON object such as "+
+		`{"tool": "read_file", "args": {"path": "main.go", "start": "1", "end": "40"}}`+
+		" and nothing else; the result comes back in the next message. "+
+		"You can use up to %d tools. When you know enough, or if the tools can't help, "+
+		"write the explanation as you normally would, without JSON.", maxSteps)
+	return b.String()
+}
+
+// parseToolCall reads a reply that is a tool call. Any other reply is the explanation.
+func parseToolCall(reply string) (ToolCall, bool) {
+	text := strings.TrimSpace(reply)
+	text = strings.TrimPrefix(text, "```json")
+	text = strings.TrimPrefix(text, "```")
+	text = strings.TrimSuffix(text, "```")
+	text = strings.TrimSpace(text)
+	if !strings.HasPrefix(text, "{") || !strings.HasSuffix(text, "}") {
+		return ToolCall{}, false
+	}
+
+	// Models send numbers as often as strings
+	var raw struct {
+		Tool string         `json:"tool"`
+		Args map[string]any `json:"args"`
+	}
+	if json.Unmarshal([]byte(text), &raw) != nil || raw.Tool == "" {
+		return ToolCall{}, false
+	}
+
+	call := ToolCall{Tool: raw.Tool, Args: make(map[string]string, len(raw.Args))}
+	for name, value := range raw.Args {
+		call.Args[name] = fmt.Sprint(value)
+	}
+	return call, true
+}
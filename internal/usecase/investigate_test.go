@@ -0,0 +1,192 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/arnislvdev/go-guru-ui/internal/infrastructure/ai"
+	"github.com/arnislvdev/go-guru-ui/internal/infrastructure/tools"
+	"github.com/arnislvdev/go-guru-ui/pkg/redact"
+)
+
+// fakeTool returns a canned result and remembers its arguments
+type fakeTool struct {
+	name   string
+	result string
+	err    error
+
+	lastArgs map[string]string
+}
+
+func (f *fakeTool) Name() string  { return f.name }
+func (f *fakeTool) Usage() string { return `{"path": "main.go"}: test tool` }
+
+func (f *fakeTool) Run(ctx context.Context, args map[string]string) (string, error) {
+	f.lastArgs = args
+	return f.result, f.err
+}
+
+func TestExplainWithTools(t *testing.T) {
+	tool := &fakeTool{name: "read_file", result: "    3  fmt.Println(\"hi\")\n"}
+	client := &fakeClient{replies: []string{
+		"```json\n{\"tool\": \"read_file\", \"args\": {\"path\": \"main.go\", \"start\": 1}}\n```",
+		"Line 3 uses fmt without importing it.",
+	}}
+	explainer := NewErrorExplainer(client, WithTools([]tools.Tool{tool}, 3))
+
+	var calls []string
+	explainer.OnToolCall = func(call ToolCall, err error) { calls = append(calls, call.String()) }
+
+	explanation, err := explainer.Explain(context.Background(), "undefined: fmt", "main.go", 3, "professional")
+	if err != nil {
+		t.Fatalf("Explain returned error: %v", err)
+	}
+	if explanation != "Line 3 uses fmt without importing it." {
+		t.Errorf("explanation = %q", explanation)
+	}
+	if len(calls) != 1 || calls[0] != "read_file path=main.go start=1" {
+		t.Errorf("tool calls = %q", calls)
+	}
+
+	// instructions, the call, its result
+	if len(client.lastMessages) != 3 || !strings.Contains(client.lastMessages[0].Content, "- read_file") ||
+		!strings.Contains(client.lastMessages[2].Content, "fmt.Println") {
+		t.Errorf("conversation = %+v", client.lastMessages)
+	}
+}
+
+func TestExplainWithToolsStepLimit(t *testing.T) {
+	call := `{"tool": "read_file", "args": {"path": "main.go"}}`
+	tool := &fakeTool{name: "read_file", result: "ok"}
+
+	// One step allowed: the second request is refused with a nudge, then the answer
+	client := &fakeClient{replies: []string{call, call, "Answer."}}
+	explainer := NewErrorExplainer(client, WithTools([]tools.Tool{tool}, 1))
+	explanation, err := explainer.Explain(context.Background(), "undefined: fmt", "", 0, "professional")
+	if err != nil || explanation != "Answer." {
+		t.Fatalf("Explain = %q, %v", explanation, err)
+	}
+	if last := client.lastMessages[len(client.lastMessages)-1].Content; !strings.Contains(last, "last tool") {
+		t.Errorf("last message = %q, want the step limit note", last)
+	}
+
+	// A model that won't stop asking fails rather than looping
+	client = &fakeClient{reply: call}
+	explainer = NewErrorExplainer(client, WithTools([]tools.Tool{tool}, 1))
+	if _, err := explainer.Explain(context.Background(), "undefined: fmt", "", 0, "professional"); err == nil {
+		t.Error("Explain should fail when the AI keeps asking for tools")
+	}
+}
+
+func TestExplainWithToolsErrorsAndRedaction(t *testing.T) {
+	redactor, err := redact.New(nil)
+	if err != nil {
+		t.Fatalf("redact.New returned error: %v", err)
+	}
+	tool := &fakeTool{name: "read_file", result: "owner: bob@example.com\n"}
+	client := &fakeClient{replies: []string{
+		`{"tool": "grep", "args": {"pattern": "x"}}`,
+		`{"tool": "read_file", "args": {"path": "/home/[USER_1]/app/main.go"}}`,
+		"Ask [EMAIL_1].",
+	}}
+	explainer := NewErrorExplainer(client, WithRedactor(redactor), WithTools([]tools.Tool{tool}, 5))
+
+	var failures []error
+	explainer.OnToolCall = func(call ToolCall, err error) {
+		if err != nil {
+			failures = append(failures, err)
+		}
+	}
+
+	explanation, err := explainer.Explain(context.Background(), "undefined: fmt", "/home/bob/app/main.go", 1, "professional")
+	if err != nil {
+		t.Fatalf("Explain returned error: %v", err)
+	}
+	if len(failures) != 1 || !strings.Contains(failures[0].Error(), `no tool named "grep"`) {
+		t.Errorf("failures = %v, want the unknown tool", failures)
+	}
+	if tool.lastArgs["path"] != "/home/bob/app/main.go" {
+		t.Errorf("tool got path %q, want the real one", tool.lastArgs["path"])
+	}
+	for _, message := range client.lastMessages {
+		if strings.Contains(message.Content, "bob@example.com") {
+			t.Errorf("tool output reached the AI unredacted: %q", message.Content)
+		}
+	}
+	if explanation != "Ask bob@example.com." {
+		t.Errorf("explanation = %q, want the address restored", explanation)
+	}
+}
+
+func TestExplainStreamWithToolsRestoresToolSecrets(t *testing.T) {
+	redactor, err := redact.New(nil)
+	if err != nil {
+		t.Fatalf("redact.New returned error: %v", err)
+	}
+	// Only the tool result holds a secret, so nothing is redacted before the first call
+	tool := &fakeTool{name: "read_file", result: "owner: bob@example.com\n"}
+	client := &fakeClient{replies: []string{`{"tool": "read_file", "args": {"path": "main.go"}}`, "Ask [EMAIL_1]."}}
+	explainer := NewErrorExplainer(client, WithRedactor(redactor), WithTools([]tools.Tool{tool}, 5))
+
+	var out strings.Builder
+	if err := explainer.ExplainStream(context.Background(), "undefined: fmt", "", 0, "professional", func(chunk string) { out.WriteString(chunk) }); err != nil {
+		t.Fatalf("ExplainStream returned error: %v", err)
+	}
+	if out.String() != "Ask bob@example.com." {
+		t.Errorf("streamed %q, want the address restored", out.String())
+	}
+}
+
+func TestExplainWithToolsChatError(t *testing.T) {
+	client := &fakeClient{err: errors.New("provider down")}
+	explainer := NewErrorExplainer(client, WithTools([]tools.Tool{&fakeTool{name: "read_file"}}, 2))
+	if err := explainer.ExplainStream(context.Background(), "undefined: fmt", "", 0, "professional", func(string) {}); err == nil {
+		t.Error("ExplainStream should return the AI error")
+	}
+}
+
+func TestExplainWithToolsUsesExplainModel(t *testing.T) {
+	var models []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Model string `json:"model"`
+		}
+		json.NewDecoder(r.Body).Decode(&req)
+		models = append(models, req.Model)
+		w.Header().Set("Content-Type", "application/x-ndjson")
+		w.Write([]byte(`{"message":{"role":"assistant","content":"Import fmt."},"done":true}` + "\n"))
+	}))
+	defer server.Close()
+
+	client, err := ai.NewLocalClient(&ai.Config{BaseURL: server.URL, Model: "codellama",
+		Models: map[string]string{ai.TaskExplain: "deepseek", ai.TaskChat: "qwen"}}, ai.LocalAPIOllama)
+	if err != nil {
+		t.Fatalf("NewLocalClient returned error: %v", err)
+	}
+
+	explainer := NewErrorExplainer(client, WithTools([]tools.Tool{&fakeTool{name: "read_file"}}, 2))
+	if _, err := explainer.Explain(context.Background(), "undefined: fmt", "", 0, "professional"); err != nil {
+		t.Fatalf("Explain returned error: %v", err)
+	}
+	if len(models) != 1 || models[0] != "deepseek" {
+		t.Errorf("models asked = %q, want the explain model deepseek", models)
+	}
+}
+
+func TestExplainWithToolsOffline(t *testing.T) {
+	client, err := ai.NewOfflineClient()
+	if err != nil {
+		t.Fatalf("NewOfflineClient returned error: %v", err)
+	}
+
+	explainer := NewErrorExplainer(client, WithTools([]tools.Tool{&fakeTool{name: "read_file"}}, 2))
+	explanation, err := explainer.Explain(context.Background(), "undefined: fmt", "", 0, "professional")
+	if err != nil || explanation == "" {
+		t.Errorf("Explain = %q, %v, want the offline explanation", explanation, err)
+	}
+}
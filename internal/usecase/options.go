@@ -0,0 +1,41 @@
+package usecase
+
+import (
+	"github.com/arnislvdev/go-guru-ui/internal/infrastructure/tools"
+	"github.com/arnislvdev/go-guru-ui/pkg/redact"
+)
+
+// Option changes how a use case talks to the AI
+type Option func(*options)
+
+type options struct {
+	redactor *redact.Redactor
+	tools    []tools.Tool
+	maxSteps int
+}
+
+// WithRedactor hides secrets and personal details from the AI with r and
+// puts them back in the answer
+func WithRedactor(r *redact.Redactor) Option {
+	return func(o *options) {
+		o.redactor = r
+	}
+}
+
+// WithTools lets the AI look at the user's code with the given read-only
+// tools before explaining, using at most maxSteps of them
+func WithTools(list []tools.Tool, maxSteps int) Option {
+	return func(o *options) {
+		o.tools = list
+		o.maxSteps = maxSteps
+	}
+}
+
+// applyOptions collects opts
+func applyOptions(opts []Option) options {
+	var o options
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
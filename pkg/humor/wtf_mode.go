@@ -37,6 +37,16 @@ func (w *WTFMode) Enhance(explanation, errorType string) string {
 	return result
 }
 
+// Prefix returns a humorous opening line, for callers that print the explanation themselves
+func (w *WTFMode) Prefix(errorType string) string {
+	return w.getRandomPrefix(errorType)
+}
+
+// Suffix returns a humorous closing line, for callers that print the explanation themselves
+func (w *WTFMode) Suffix(errorType string) string {
+	return w.getRandomSuffix(errorType)
+}
+
 // getRandomPrefix returns a random humorous prefix
 func (w *WTFMode) getRandomPrefix(errorType string) string {
 	prefixes := w.responses["prefixes"]
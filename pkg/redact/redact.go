@@ -0,0 +1,249 @@
+package redact
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"os/user"
+	"regexp"
+	"strings"
+)
+
+// Placeholder kinds
+const (
+	KindKey    = "KEY"
+	KindJWT    = "JWT"
+	KindSecret = "SECRET"
+	KindEmail  = "EMAIL"
+	KindHost   = "HOST"
+	KindIP     = "IP"
+	KindUser   = "USER"
+	KindCustom = "REDACTED"
+)
+
+// maxPlaceholder is longer than any placeholder a session hands out, e.g. [REDACTED_12]
+const maxPlaceholder = 24
+
+// placeholderPattern finds placeholders in text coming back from the AI
+var placeholderPattern = regexp.MustCompile(`\[[A-Z]+_[0-9]+\]`)
+
+// detector finds one kind of sensitive value. When the pattern has a group,
+// only the first group is hidden, so "password=hunter2" keeps its "password=".
+type detector struct {
+	kind    string
+	pattern *regexp.Regexp
+	keep    func(string) bool // optional; values it returns true for are left alone
+}
+
+// builtins are the detectors every Redactor starts with, in the order they run.
+// Keys and tokens go first so an email inside a URL doesn't split a token.
+var builtins = []detector{
+	{kind: KindKey, pattern: regexp.MustCompile(`-----BEGIN [A-Z ]*PRIVATE KEY-----[\s\S]*?-----END [A-Z ]*PRIVATE KEY-----`)},
+	{kind: KindJWT, pattern: regexp.MustCompile(`\beyJ[A-Za-z0-9_-]{5,}\.eyJ[A-Za-z0-9_-]{5,}\.[A-Za-z0-9_-]{5,}`)},
+	{kind: KindKey, pattern: regexp.MustCompile(`\bsk-(?:ant-|proj-)?[A-Za-z0-9_-]{20,}`)},
+	{kind: KindKey, pattern: regexp.MustCompile(`\b(?:AKIA|ASIA)[0-9A-Z]{16}\b`)},
+	{kind: KindKey, pattern: regexp.MustCompile(`\b(?:gh[pousr]_[A-Za-z0-9]{36,}|github_pat_[A-Za-z0-9_]{22,})`)},
+	{kind: KindKey, pattern: regexp.MustCompile(`\bxox[abprs]-[A-Za-z0-9-]{10,}`)},
+	{kind: KindKey, pattern: regexp.MustCompile(`\bAIza[0-9A-Za-z_-]{35}`)},
+	{kind: KindKey, pattern: regexp.MustCompile(`\b(?:sk|pk|rk)_(?:live|test)_[0-9A-Za-z]{16,}`)},
+	{kind: KindSecret, pattern: regexp.MustCompile(`(?i)\bbearer\s+([A-Za-z0-9._~+/-]{16,}=*)`)},
+	{kind: KindSecret, pattern: regexp.MustCompile(`(?i)\b(?:password|passwd|pwd|secret|token|api[_-]?key|access[_-]?key|auth)\s*[:=]\s*["']?([^\s"'&,;\[]{6,})`)},
+	{kind: KindSecret, pattern: regexp.MustCompile(`://[^/\s:@]+:([^/\s@\[]+)@`)},
+	{kind: KindEmail, pattern: regexp.MustCompile(`\b[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}\b`)},
+	{kind: KindHost, pattern: regexp.MustCompile(`(?i)\b[a-z0-9-]+(?:\.[a-z0-9-]+)*\.(?:internal|local|lan|corp|intranet|localdomain)\b`)},
+	{kind: KindIP, pattern: regexp.MustCompile(`\b(?:[0-9]{1,3}\.){3}[0-9]{1,3}\b`), keep: publicSafeIP},
+	{kind: KindIP, pattern: regexp.MustCompile(`(?i)\b[0-9a-f]{0,4}(?::[0-9a-f]{0,4}){2,7}\b`), keep: publicSafeIP},
+	{kind: KindUser, pattern: regexp.MustCompile(`(?:/home/|/Users/|[A-Za-z]:\\Users\\)([^/\\\s\[]+)`), keep: sharedHome},
+}
+
+// Redactor hides secrets and personal details in text before it leaves the
+// machine. It is safe to share; each request gets its own Session.
+type Redactor struct {
+	detectors []detector
+}
+
+// New creates a Redactor with the built-in detectors, the current user's
+// name, and the given custom patterns. A custom pattern with a group hides
+// only its first group.
+func New(patterns []string) (*Redactor, error) {
+	return newRedactor(patterns, currentUser())
+}
+
+// newRedactor is New with the user name given, so tests don't depend on who runs them
+func newRedactor(patterns []string, username string) (*Redactor, error) {
+	r := &Redactor{detectors: append([]detector(nil), builtins...)}
+
+	// Short or shared names like "root" would hide ordinary words
+	if len(username) >= 3 && !sharedHome(username) {
+		r.detectors = append(r.detectors, detector{
+			kind:    KindUser,
+			pattern: regexp.MustCompile(`\b` + regexp.QuoteMeta(username) + `\b`),
+		})
+	}
+
+	for _, pattern := range patterns {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid redact pattern %q: %w", pattern, err)
+		}
+		r.detectors = append(r.detectors, detector{kind: KindCustom, pattern: re})
+	}
+	return r, nil
+}
+
+// Session returns a fresh set of placeholders for one request. A nil
+// Redactor gives a nil Session, which changes nothing.
+func (r *Redactor) Session() *Session {
+	if r == nil {
+		return nil
+	}
+	return &Session{
+		redactor: r,
+		byValue:  make(map[string]string),
+		byHolder: make(map[string]string),
+		counts:   make(map[string]int),
+	}
+}
+
+// Session redacts the text of one request, giving the same value the same
+// placeholder every time, and puts the values back into the answer
+type Session struct {
+	redactor *Redactor
+	byValue  map[string]string // value -> placeholder
+	byHolder map[string]string // placeholder -> value
+	counts   map[string]int    // placeholders handed out per kind
+}
+
+// Redact replaces sensitive values in text with placeholders like [EMAIL_1]
+func (s *Session) Redact(text string) string {
+	if s == nil || text == "" {
+		return text
+	}
+
+	for _, d := range s.redactor.detectors {
+		text = s.apply(d, text)
+	}
+	return text
+}
+
+// apply replaces everything d finds in text
+func (s *Session) apply(d detector, text string) string {
+	var out strings.Builder
+	last := 0
+	for _, match := range d.pattern.FindAllStringSubmatchIndex(text, -1) {
+		start, end := match[0], match[1]
+		if len(match) >= 4 && match[2] >= 0 {
+			start, end = match[2], match[3]
+		}
+		value := text[start:end]
+		if value == "" || placeholderPattern.MatchString(value) || (d.keep != nil && d.keep(value)) {
+			continue
+		}
+
+		out.WriteString(text[last:start])
+		out.WriteString(s.placeholder(d.kind, value))
+		last = end
+	}
+	if last == 0 {
+		return text
+	}
+	out.WriteString(text[last:])
+	return out.String()
+}
+
+// placeholder returns the placeholder for value, handing out a new one the first time
+func (s *Session) placeholder(kind, value string) string {
+	if holder, ok := s.byValue[value]; ok {
+		return holder
+	}
+	s.counts[kind]++
+	holder := fmt.Sprintf("[%s_%d]", kind, s.counts[kind])
+	s.byValue[value] = holder
+	s.byHolder[holder] = value
+	return holder
+}
+
+// Count returns how many different values the session has hidden
+func (s *Session) Count() int {
+	if s == nil {
+		return 0
+	}
+	return len(s.byValue)
+}
+
+// Restore puts the hidden values back in place of their placeholders.
+// Placeholders the session didn't hand out are left as they are.
+func (s *Session) Restore(text string) string {
+	if s == nil || len(s.byHolder) == 0 {
+		return text
+	}
+	return placeholderPattern.ReplaceAllStringFunc(text, func(holder string) string {
+		if value, ok := s.byHolder[holder]; ok {
+			return value
+		}
+		return holder
+	})
+}
+
+// Stream returns write and flush functions that restore placeholders in
+// streamed text before passing it to onChunk. A placeholder split between
+// chunks is held back until it is complete; flush sends whatever is left.
+func (s *Session) Stream(onChunk func(string)) (write func(string), flush func()) {
+	if s == nil || len(s.byHolder) == 0 {
+		return onChunk, func() {}
+	}
+
+	var pending string
+	write = func(chunk string) {
+		pending += chunk
+
+		// Hold back an opening bracket that may still become a placeholder
+		cut := len(pending)
+		if i := strings.LastIndexByte(pending, '['); i >= 0 && !strings.Contains(pending[i:], "]") && len(pending)-i < maxPlaceholder {
+			cut = i
+		}
+		if cut > 0 {
+			onChunk(s.Restore(pending[:cut]))
+			pending = pending[cut:]
+		}
+	}
+	flush = func() {
+		if pending != "" {
+			onChunk(s.Restore(pending))
+			pending = ""
+		}
+	}
+	return write, flush
+}
+
+// publicSafeIP keeps addresses that say nothing about anyone: loopback,
+// unspecified, and strings that only look like addresses
+func publicSafeIP(value string) bool {
+	ip := net.ParseIP(value)
+	return ip == nil || ip.IsLoopback() || ip.IsUnspecified()
+}
+
+// sharedHome keeps home folder names that aren't a person
+func sharedHome(name string) bool {
+	switch strings.ToLower(name) {
+	case "shared", "public", "default", "runner", "root":
+		return true
+	}
+	return false
+}
+
+// currentUser returns the login name of whoever runs GuruUI
+func currentUser() string {
+	if u, err := user.Current(); err == nil && u.Username != "" {
+		name := u.Username
+		// Windows names come as DOMAIN\name
+		if i := strings.LastIndexByte(name, '\\'); i >= 0 {
+			name = name[i+1:]
+		}
+		return name
+	}
+	if name := os.Getenv("USER"); name != "" {
+		return name
+	}
+	return os.Getenv("USERNAME")
+}